@@ -0,0 +1,218 @@
+package errx
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CodeDef declares an error code and its default metadata in the catalog.
+type CodeDef struct {
+	// Code is the machine-readable error code, as passed to WithCode.
+	Code string
+
+	// Type is the default type of errors created with this code.
+	Type Type
+
+	// Message is the default message of errors created with this code
+	// when no message is given. It may be a template with "{name}" placeholders,
+	// filled in with WithParams.
+	Message string
+
+	// Description explains when the error occurs. It is intended for documentation.
+	Description string
+
+	// Owner is the team or service responsible for the error.
+	Owner string
+
+	// Aliases are former names of the code.
+	// Aliases are normalized to Code when errors are decoded, matched with IsCodeIn,
+	// or created with WithCode. Creating an error with an alias triggers the deprecation hook.
+	Aliases []string
+
+	// Deprecated marks a code that should no longer be produced.
+	// Creating an error with a deprecated code triggers the deprecation hook
+	// (see SetDeprecatedCodeHook).
+	Deprecated bool
+}
+
+// StrictMode controls how undeclared codes passed to WithCode are handled.
+type StrictMode uint8
+
+const (
+	// StrictOff accepts undeclared codes silently. This is the default.
+	StrictOff StrictMode = iota
+
+	// StrictReport reports undeclared codes to the handler set with SetUndeclaredCodeHandler.
+	StrictReport
+
+	// StrictPanic panics on undeclared codes.
+	StrictPanic
+)
+
+// catalog is the registry of declared error codes.
+var catalog = struct {
+	sync.RWMutex
+	defs       map[string]CodeDef
+	aliases    map[string]string
+	strict     StrictMode
+	undeclared func(code string)
+	deprecated func(code string, err ErrorX)
+}{
+	defs:       make(map[string]CodeDef),
+	aliases:    make(map[string]string),
+	undeclared: logUndeclaredCode,
+}
+
+// Declare adds error codes to the catalog.
+//
+// Once a code is declared, New and Wrap fill in its default type and message
+// when the code is set with WithCode and no explicit type or message is given.
+//
+// Declare panics if a code is empty or declared more than once, either as a code
+// or as an alias, so it is intended to be called from package initialization.
+func Declare(defs ...CodeDef) {
+	catalog.Lock()
+	defer catalog.Unlock()
+
+	for _, def := range defs {
+		if def.Code == "" {
+			panic("errx: cannot declare an empty error code")
+		}
+		if isDeclared(def.Code) {
+			panic(fmt.Sprintf("errx: error code %q is declared more than once", def.Code))
+		}
+		catalog.defs[def.Code] = def
+
+		for _, alias := range def.Aliases {
+			if alias == "" || isDeclared(alias) {
+				panic(fmt.Sprintf("errx: alias %q of error code %q is empty or already declared", alias, def.Code))
+			}
+			catalog.aliases[alias] = def.Code
+		}
+	}
+}
+
+// CanonicalCode returns the code an alias was declared for.
+// Codes that are not aliases are returned unchanged.
+func CanonicalCode(code string) string {
+	catalog.RLock()
+	defer catalog.RUnlock()
+
+	if canonical, ok := catalog.aliases[code]; ok {
+		return canonical
+	}
+	return code
+}
+
+// SetDeprecatedCodeHook sets the function called when an error is created with
+// a deprecated code or an alias. The hook receives the code as passed to WithCode
+// and the created error, whose trace shows where the code is still produced.
+// Passing nil removes the hook.
+func SetDeprecatedCodeHook(fn func(code string, err ErrorX)) {
+	catalog.Lock()
+	defer catalog.Unlock()
+
+	catalog.deprecated = fn
+}
+
+// LookupCode returns the declaration of the given code.
+// The boolean result reports whether the code is declared.
+func LookupCode(code string) (CodeDef, bool) {
+	catalog.RLock()
+	defer catalog.RUnlock()
+
+	def, ok := catalog.defs[code]
+	return def, ok
+}
+
+// DeclaredCodes returns all declared codes sorted by code.
+func DeclaredCodes() []CodeDef {
+	catalog.RLock()
+	defer catalog.RUnlock()
+
+	defs := make([]CodeDef, 0, len(catalog.defs))
+	for _, def := range catalog.defs {
+		defs = append(defs, def)
+	}
+	slices.SortFunc(defs, func(a, b CodeDef) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+	return defs
+}
+
+// SetStrictMode sets how undeclared codes passed to WithCode are handled.
+// DefaultCode is always accepted.
+func SetStrictMode(mode StrictMode) {
+	catalog.Lock()
+	defer catalog.Unlock()
+
+	catalog.strict = mode
+}
+
+// SetUndeclaredCodeHandler sets the function called for undeclared codes in StrictReport mode.
+// By default, undeclared codes are written to the standard logger.
+// Passing nil restores the default handler.
+func SetUndeclaredCodeHandler(fn func(code string)) {
+	catalog.Lock()
+	defer catalog.Unlock()
+
+	if fn == nil {
+		fn = logUndeclaredCode
+	}
+	catalog.undeclared = fn
+}
+
+// applyCatalog fills in the catalog defaults for the code set on the error
+// and enforces the strict mode for undeclared codes.
+func applyCatalog(e *errorX) {
+	used := e.code
+
+	catalog.RLock()
+	canonical, isAlias := catalog.aliases[e.code]
+	if isAlias {
+		e.code = canonical
+	}
+	def, ok := catalog.defs[e.code]
+	strict, undeclared, deprecated := catalog.strict, catalog.undeclared, catalog.deprecated
+	catalog.RUnlock()
+
+	if !ok {
+		if e.code == DefaultCode {
+			return
+		}
+		switch strict {
+		case StrictReport:
+			undeclared(e.code)
+		case StrictPanic:
+			panic(fmt.Sprintf("errx: undeclared error code %q", e.code))
+		}
+		return
+	}
+
+	if !e.typeSet {
+		e.type_ = def.Type
+	}
+	if e.msg == "" && e.template == "" && def.Message != "" {
+		e.template = def.Message
+	}
+
+	if (isAlias || def.Deprecated) && deprecated != nil {
+		deprecated(used, e)
+	}
+}
+
+// isDeclared reports whether the code is declared as a code or an alias.
+// The caller must hold the catalog lock.
+func isDeclared(code string) bool {
+	_, isCode := catalog.defs[code]
+	_, isAlias := catalog.aliases[code]
+	return isCode || isAlias
+}
+
+// logUndeclaredCode is the default handler for undeclared codes.
+func logUndeclaredCode(code string) {
+	log.Printf("errx: undeclared error code %q", code)
+}
@@ -0,0 +1,224 @@
+package errx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/code19m/errx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	errx.Declare(
+		errx.CodeDef{
+			Code:        "CATALOG_USER_NOT_FOUND",
+			Type:        errx.T_NotFound,
+			Message:     "user not found",
+			Description: "The requested user does not exist.",
+			Owner:       "identity",
+		},
+		errx.CodeDef{
+			Code: "CATALOG_EMAIL_TAKEN",
+			Type: errx.T_Conflict,
+		},
+		errx.CodeDef{
+			Code:    "CATALOG_ACCOUNT_NOT_FOUND",
+			Type:    errx.T_NotFound,
+			Aliases: []string{"CATALOG_ACCT_MISSING"},
+		},
+		errx.CodeDef{Code: "CATALOG_LEGACY", Deprecated: true},
+		errx.CodeDef{Code: "CATALOG_CURRENT", Aliases: []string{"CATALOG_OLD"}},
+	)
+}
+
+func TestDeclare(t *testing.T) {
+	t.Run("duplicate declaration panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic on duplicate declaration")
+			}
+		}()
+		errx.Declare(errx.CodeDef{Code: "CATALOG_USER_NOT_FOUND"})
+	})
+
+	t.Run("empty code panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic on empty code")
+			}
+		}()
+		errx.Declare(errx.CodeDef{})
+	})
+}
+
+func TestCatalogDefaults(t *testing.T) {
+	t.Run("new fills in type and message", func(t *testing.T) {
+		e := errx.New("", errx.WithCode("CATALOG_USER_NOT_FOUND")).(errx.ErrorX)
+		if e.Type() != errx.T_NotFound {
+			t.Errorf("expected type T_NotFound, got %v", e.Type())
+		}
+		if e.Error() != "user not found" {
+			t.Errorf("expected default message, got %v", e.Error())
+		}
+	})
+
+	t.Run("explicit type and message win", func(t *testing.T) {
+		e := errx.New("user 42 not found",
+			errx.WithType(errx.T_Validation),
+			errx.WithCode("CATALOG_USER_NOT_FOUND"),
+		).(errx.ErrorX)
+		if e.Type() != errx.T_Validation {
+			t.Errorf("expected type T_Validation, got %v", e.Type())
+		}
+		if e.Error() != "user 42 not found" {
+			t.Errorf("expected explicit message, got %v", e.Error())
+		}
+	})
+
+	t.Run("wrap fills in type", func(t *testing.T) {
+		err := errx.Wrap(errors.New("duplicate key"), errx.WithCode("CATALOG_EMAIL_TAKEN"))
+		if errx.GetType(err) != errx.T_Conflict {
+			t.Errorf("expected type T_Conflict, got %v", errx.GetType(err))
+		}
+		if err.Error() != "duplicate key" {
+			t.Errorf("expected wrapped message, got %v", err.Error())
+		}
+	})
+
+	t.Run("wrap keeps type of earlier options", func(t *testing.T) {
+		err := errx.New("taken", errx.WithCode("CATALOG_EMAIL_TAKEN"), errx.WithType(errx.T_Validation))
+		err = errx.Wrap(err)
+		if errx.GetType(err) != errx.T_Validation {
+			t.Errorf("expected type T_Validation, got %v", errx.GetType(err))
+		}
+	})
+}
+
+func TestLookupCode(t *testing.T) {
+	def, ok := errx.LookupCode("CATALOG_USER_NOT_FOUND")
+	if !ok {
+		t.Fatalf("expected code to be declared")
+	}
+	if def.Owner != "identity" || def.Description == "" {
+		t.Errorf("unexpected declaration: %+v", def)
+	}
+
+	if _, ok := errx.LookupCode("CATALOG_USER_NOT_FOUD"); ok {
+		t.Errorf("expected misspelled code to be undeclared")
+	}
+}
+
+func TestDeclaredCodes(t *testing.T) {
+	defs := errx.DeclaredCodes()
+
+	var found int
+	for i, def := range defs {
+		if i > 0 && defs[i-1].Code >= def.Code {
+			t.Errorf("expected codes sorted, got %v before %v", defs[i-1].Code, def.Code)
+		}
+		if def.Code == "CATALOG_USER_NOT_FOUND" || def.Code == "CATALOG_EMAIL_TAKEN" {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("expected declared codes to be listed, got %v", defs)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	defer errx.SetStrictMode(errx.StrictOff)
+	defer errx.SetUndeclaredCodeHandler(nil)
+
+	t.Run("report undeclared code", func(t *testing.T) {
+		var reported []string
+		errx.SetUndeclaredCodeHandler(func(code string) {
+			reported = append(reported, code)
+		})
+		errx.SetStrictMode(errx.StrictReport)
+
+		_ = errx.New("error", errx.WithCode("CATALOG_USER_NOT_FOUD"))
+		_ = errx.New("error", errx.WithCode("CATALOG_USER_NOT_FOUND"))
+		_ = errx.New("error", errx.WithCode(errx.DefaultCode))
+
+		if len(reported) != 1 || reported[0] != "CATALOG_USER_NOT_FOUD" {
+			t.Errorf("expected only the undeclared code to be reported, got %v", reported)
+		}
+	})
+
+	t.Run("panic on undeclared code", func(t *testing.T) {
+		errx.SetStrictMode(errx.StrictPanic)
+
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic on undeclared code")
+			}
+		}()
+		_ = errx.New("error", errx.WithCode("CATALOG_USER_NOT_FOUD"))
+	})
+}
+
+func TestAliases(t *testing.T) {
+	t.Run("canonical code", func(t *testing.T) {
+		if code := errx.CanonicalCode("CATALOG_ACCT_MISSING"); code != "CATALOG_ACCOUNT_NOT_FOUND" {
+			t.Errorf("unexpected canonical code: %v", code)
+		}
+		if code := errx.CanonicalCode("OTHER"); code != "OTHER" {
+			t.Errorf("expected unknown code unchanged, got %v", code)
+		}
+	})
+
+	t.Run("alias collision panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic on alias collision")
+			}
+		}()
+		errx.Declare(errx.CodeDef{Code: "CATALOG_ACCT_MISSING"})
+	})
+
+	t.Run("creation normalizes alias", func(t *testing.T) {
+		err := errx.New("missing", errx.WithCode("CATALOG_ACCT_MISSING"))
+		if errx.GetCode(err) != "CATALOG_ACCOUNT_NOT_FOUND" || errx.GetType(err) != errx.T_NotFound {
+			t.Errorf("unexpected code or type: %v, %v", errx.GetCode(err), errx.GetType(err))
+		}
+	})
+
+	t.Run("decoding normalizes alias", func(t *testing.T) {
+		_, err := errx.FromGRPCError(status.Error(codes.NotFound, "missing"), errx.WithCode("CATALOG_ACCT_MISSING"))
+		if errx.GetCode(err) != "CATALOG_ACCOUNT_NOT_FOUND" {
+			t.Errorf("unexpected code: %v", errx.GetCode(err))
+		}
+
+		_, err = errx.FromJSON([]byte(`{"code":"CATALOG_ACCT_MISSING","message":"missing","type":"T_NotFound"}`))
+		if errx.GetCode(err) != "CATALOG_ACCOUNT_NOT_FOUND" {
+			t.Errorf("unexpected code: %v", errx.GetCode(err))
+		}
+	})
+
+	t.Run("IsCodeIn normalizes alias", func(t *testing.T) {
+		err := errx.New("missing", errx.WithCode("CATALOG_ACCOUNT_NOT_FOUND"))
+		if !errx.IsCodeIn(err, "CATALOG_ACCT_MISSING") {
+			t.Errorf("expected alias to match canonical code")
+		}
+	})
+}
+
+func TestDeprecatedCodeHook(t *testing.T) {
+	var used []string
+	errx.SetDeprecatedCodeHook(func(code string, err errx.ErrorX) {
+		used = append(used, code)
+		if !contains(err.Trace(), "catalog_test.go") {
+			t.Errorf("expected trace to point to the creation site, got: %v", err.Trace())
+		}
+	})
+	defer errx.SetDeprecatedCodeHook(nil)
+
+	_ = errx.New("error", errx.WithCode("CATALOG_LEGACY"))
+	_ = errx.New("error", errx.WithCode("CATALOG_OLD"))
+	_ = errx.New("error", errx.WithCode("CATALOG_CURRENT"))
+
+	if len(used) != 2 || used[0] != "CATALOG_LEGACY" || used[1] != "CATALOG_OLD" {
+		t.Errorf("unexpected hook calls: %v", used)
+	}
+}
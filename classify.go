@@ -0,0 +1,119 @@
+package errx
+
+import "errors"
+
+// IsClientFault reports whether errors of this type are caused by the caller,
+// for example invalid input or missing permissions.
+// Such errors should not page the service owners.
+func (t Type) IsClientFault() bool {
+	switch t {
+	case T_Validation, T_NotFound, T_Conflict, T_Authentication, T_Forbidden,
+		T_Throttling, T_Canceled, T_PreconditionFailed, T_TooLarge:
+		return true
+	}
+	return false
+}
+
+// IsServerFault reports whether errors of this type are caused by the service
+// or its dependencies. Unknown types are considered server faults.
+func (t Type) IsServerFault() bool {
+	return !t.IsClientFault()
+}
+
+// IsRetryable reports whether a request failing with this type of error
+// may succeed when retried unchanged, usually after a backoff.
+func (t Type) IsRetryable() bool {
+	switch t {
+	case T_Throttling, T_Unavailable, T_Timeout:
+		return true
+	}
+	return false
+}
+
+// IsTemporary reports whether errors of this type describe a condition
+// that is expected to resolve by itself.
+func (t Type) IsTemporary() bool {
+	switch t {
+	case T_Throttling, T_Unavailable, T_Timeout:
+		return true
+	}
+	return false
+}
+
+// Timeout reports whether the error is a timeout, by its type or by the wrapped error.
+// It follows the net.Error convention, so errx errors can be checked like network errors,
+// and wrapping a network error keeps its answer.
+func (e errorX) Timeout() bool {
+	return e.type_ == T_Timeout || isTimeout(e.origin)
+}
+
+// Temporary reports whether the error is temporary.
+// It follows the net.Error convention and respects the WithTemporary override.
+// Without an override, the wrapped error decides if it has a `Temporary() bool` method,
+// otherwise the default of the error type is used.
+func (e errorX) Temporary() bool {
+	if e.temporary != nil {
+		return *e.temporary
+	}
+	var t interface{ Temporary() bool }
+	if errors.As(e.origin, &t) {
+		return t.Temporary()
+	}
+	return e.type_.IsTemporary()
+}
+
+// Retryable reports whether the failed operation may succeed when retried.
+// It respects the WithRetryable override.
+func (e errorX) Retryable() bool {
+	if e.retryable != nil {
+		return *e.retryable
+	}
+	return e.type_.IsRetryable()
+}
+
+// IsClientFault reports whether the error is caused by the caller, based on its type.
+func IsClientFault(err error) bool {
+	return GetType(err).IsClientFault()
+}
+
+// IsServerFault reports whether the error is caused by the service or its dependencies, based on its type.
+// Errors that do not implement the ErrorX interface are considered server faults.
+func IsServerFault(err error) bool {
+	return GetType(err).IsServerFault()
+}
+
+// IsRetryable reports whether the failed operation may succeed when retried.
+//
+// The first error in the chain implementing a `Retryable() bool` method, including ErrorX, decides.
+// Otherwise, the default of the error type is used.
+// If the error is nil, false is returned.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if r, ok := Find[interface {
+		error
+		Retryable() bool
+	}](err); ok {
+		return r.Retryable()
+	}
+	return GetType(err).IsRetryable()
+}
+
+// IsTemporary reports whether the error is temporary.
+//
+// The first error in the chain implementing a `Temporary() bool` method, including ErrorX and net.Error, decides.
+// Otherwise, the default of the error type is used.
+// If the error is nil, false is returned.
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	if t, ok := Find[interface {
+		error
+		Temporary() bool
+	}](err); ok {
+		return t.Temporary()
+	}
+	return GetType(err).IsTemporary()
+}
@@ -0,0 +1,168 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestTypeClassification(t *testing.T) {
+	testCases := []struct {
+		typ         errx.Type
+		clientFault bool
+		retryable   bool
+		temporary   bool
+	}{
+		{errx.T_Internal, false, false, false},
+		{errx.T_Validation, true, false, false},
+		{errx.T_NotFound, true, false, false},
+		{errx.T_Conflict, true, false, false},
+		{errx.T_Authentication, true, false, false},
+		{errx.T_Forbidden, true, false, false},
+		{errx.T_Throttling, true, true, true},
+		{errx.T_Unavailable, false, true, true},
+		{errx.T_Timeout, false, true, true},
+		{errx.T_Canceled, true, false, false},
+		{errx.T_PreconditionFailed, true, false, false},
+		{errx.T_Unimplemented, false, false, false},
+		{errx.T_TooLarge, true, false, false},
+		{errx.Type(99), false, false, false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.typ.IsClientFault(); got != tc.clientFault {
+			t.Errorf("%v: IsClientFault = %v, want %v", tc.typ, got, tc.clientFault)
+		}
+		if got := tc.typ.IsServerFault(); got == tc.clientFault {
+			t.Errorf("%v: IsServerFault = %v, want %v", tc.typ, got, !tc.clientFault)
+		}
+		if got := tc.typ.IsRetryable(); got != tc.retryable {
+			t.Errorf("%v: IsRetryable = %v, want %v", tc.typ, got, tc.retryable)
+		}
+		if got := tc.typ.IsTemporary(); got != tc.temporary {
+			t.Errorf("%v: IsTemporary = %v, want %v", tc.typ, got, tc.temporary)
+		}
+	}
+}
+
+func TestClassificationPredicates(t *testing.T) {
+	t.Run("type defaults", func(t *testing.T) {
+		err := errx.New("dependency down", errx.WithType(errx.T_Unavailable))
+		if !errx.IsServerFault(err) || errx.IsClientFault(err) {
+			t.Errorf("expected server fault")
+		}
+		if !errx.IsRetryable(err) || !errx.IsTemporary(err) {
+			t.Errorf("expected retryable and temporary error")
+		}
+	})
+
+	t.Run("per-error overrides", func(t *testing.T) {
+		err := errx.New("payment in progress",
+			errx.WithType(errx.T_Conflict),
+			errx.WithRetryable(true),
+			errx.WithTemporary(true),
+		)
+		if !errx.IsRetryable(err) || !errx.IsTemporary(err) {
+			t.Errorf("expected overrides to apply")
+		}
+
+		err = errx.Wrap(errx.New("quota exhausted", errx.WithType(errx.T_Throttling)), errx.WithRetryable(false))
+		if errx.IsRetryable(err) {
+			t.Errorf("expected override to apply to wrapped error")
+		}
+		if !errx.IsTemporary(err) {
+			t.Errorf("expected type default for temporary")
+		}
+	})
+
+	t.Run("overrides survive wrapping", func(t *testing.T) {
+		err := errx.Wrap(errx.New("flaky", errx.WithRetryable(true)))
+		if !errx.IsRetryable(err) {
+			t.Errorf("expected override to survive wrapping")
+		}
+	})
+
+	t.Run("overrides are found through non-ErrorX wrappers", func(t *testing.T) {
+		err := fmt.Errorf("charge: %w", errx.New("payment in progress", errx.WithRetryable(true), errx.WithTemporary(true)))
+		if !errx.IsRetryable(err) || !errx.IsTemporary(err) {
+			t.Errorf("expected overrides to apply through fmt.Errorf")
+		}
+	})
+
+	t.Run("overrides are sent to peers", func(t *testing.T) {
+		err := errx.New("quota exhausted", errx.WithType(errx.T_Throttling), errx.WithRetryable(false))
+
+		_, received := errx.FromGRPCError(errx.ToGRPCError(err))
+		if errx.IsRetryable(received) || !errx.IsTemporary(received) {
+			t.Errorf("expected override to survive gRPC")
+		}
+
+		data, jerr := json.Marshal(err)
+		if jerr != nil {
+			t.Fatalf("unexpected error: %v", jerr)
+		}
+		_, received = errx.FromJSON(data)
+		if errx.IsRetryable(received) || !errx.IsTemporary(received) {
+			t.Errorf("expected override to survive JSON")
+		}
+	})
+
+	t.Run("regular errors", func(t *testing.T) {
+		err := errors.New("regular error")
+		if !errx.IsServerFault(err) || errx.IsRetryable(err) || errx.IsTemporary(err) {
+			t.Errorf("expected non-retryable server fault")
+		}
+		if errx.IsRetryable(nil) || errx.IsTemporary(nil) {
+			t.Errorf("expected false for nil error")
+		}
+	})
+}
+
+func TestNetErrorConventions(t *testing.T) {
+	err := errx.New("deadline exceeded", errx.WithType(errx.T_Timeout))
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) {
+		t.Fatalf("expected ErrorX to implement net.Error")
+	}
+	if !netErr.Timeout() {
+		t.Errorf("expected Timeout to be true")
+	}
+	if !netErr.Temporary() {
+		t.Errorf("expected Temporary to be true")
+	}
+
+	if errx.New("bad input", errx.WithType(errx.T_Validation)).(net.Error).Timeout() {
+		t.Errorf("expected Timeout to be false")
+	}
+}
+
+func TestWrappedNetError(t *testing.T) {
+	netErr := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	if !netErr.Timeout() || !netErr.Temporary() {
+		t.Fatalf("expected a temporary timeout")
+	}
+
+	err := errx.Wrap(netErr)
+	if errx.GetType(err) != errx.T_Timeout {
+		t.Errorf("expected type T_Timeout, got %v", errx.GetType(err))
+	}
+	if !errx.IsTemporary(err) || !errx.IsRetryable(err) || !os.IsTimeout(err) {
+		t.Errorf("expected wrapped error to keep the answers of the network error")
+	}
+
+	err = errx.Wrap(netErr, errx.WithType(errx.T_Unavailable))
+	if !err.(net.Error).Timeout() {
+		t.Errorf("expected Timeout to fall back to the wrapped error")
+	}
+
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	if err := errx.Wrap(refused, errx.WithType(errx.T_Unavailable)); errx.IsTemporary(err) || err.(net.Error).Timeout() {
+		t.Errorf("expected Temporary to fall back to the wrapped error")
+	}
+}
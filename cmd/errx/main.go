@@ -0,0 +1,144 @@
+// Command errx is a set of tools for working with errx errors.
+//
+// The trace subcommand reads log lines from stdin, finds the traces in them
+// (as rendered by ErrorX.Trace with the default renderer) and prints each trace
+// as a tree of the services it went through:
+//
+//	$ kubectl logs deploy/api | errx trace
+//	line 12:
+//	└─ (this service)
+//	   [handler.go:10] api.Get
+//	   └─ users
+//	      [client.go:20] users.call
+//	      [server.go:30] users.(*Server).handle
+//
+// Lines holding JSON objects are searched in their string values,
+// so traces escaped in structured logs are found too.
+//
+// Usage:
+//
+//	errx trace < app.log
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/code19m/errx"
+)
+
+const usage = "usage: errx trace < logs"
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "errx:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+
+	switch args[0] {
+	case "trace":
+		if len(args) > 1 {
+			return fmt.Errorf("trace takes no arguments\n%s", usage)
+		}
+		return printTraces(in, out)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+// traceRe matches a trace in the default format: frames like "[service.go:12] users.Get",
+// separated by the default separator and optionally preceded by ">>> service >>> " markers.
+// Function names end at quotes and commas, so that traces quoted in log lines are matched without them.
+// Collapsed frames, like "[tree.go:4] tree.walk (x3)", and placeholders, like "... 7 frames omitted", are matched too.
+var traceRe = regexp.MustCompile(
+	`(?:>>> [^>]+ >>> )*` + frameExpr + `(?:` + regexp.QuoteMeta(errx.DefaultTraceSeparator) +
+		`(?:>>> [^>]+ >>> )*` + frameExpr + `)*`,
+)
+
+const frameExpr = `(?:\[[^\]\s]+:\d+\] [^\s"',]+(?: \(x\d+\))?|\.\.\. \d+ frames omitted)`
+
+// frameRenderer renders frames as they were written in the log.
+var frameRenderer = errx.TraceRenderer{Paths: errx.PathAbsolute, Functions: errx.FunctionQualified}
+
+// printTraces prints the traces found in the log lines read from in.
+// Lines that cannot be parsed are skipped.
+func printTraces(in io.Reader, out io.Writer) error {
+	w := bufio.NewWriter(out)
+
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		for _, s := range lineStrings(sc.Text()) {
+			for _, trace := range traceRe.FindAllString(s, -1) {
+				hops, err := errx.ParseTrace(trace)
+				if err != nil || len(hops) == 0 {
+					continue
+				}
+				fmt.Fprintf(w, "line %d:\n", n)
+				writeTree(w, hops)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// lineStrings returns the strings of a log line to search for traces:
+// the string values of a JSON line, or the line itself.
+func lineStrings(line string) []string {
+	var v any
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") || json.Unmarshal([]byte(line), &v) != nil {
+		return []string{line}
+	}
+
+	var ss []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			ss = append(ss, v)
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				walk(v[k])
+			}
+		}
+	}
+	walk(v)
+	return ss
+}
+
+// writeTree writes the hops of a trace as a tree, each hop nested under the previous one.
+func writeTree(w io.Writer, hops []errx.TraceHop) {
+	for i, hop := range hops {
+		indent := strings.Repeat("   ", i)
+
+		service := hop.Service
+		if service == "" {
+			service = "(this service)"
+		}
+		fmt.Fprintf(w, "%s└─ %s\n", indent, service)
+
+		for _, f := range hop.Frames {
+			fmt.Fprintf(w, "%s   %s\n", indent, frameRenderer.RenderFrame(f))
+		}
+	}
+}
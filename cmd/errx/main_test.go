@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintTraces(t *testing.T) {
+	t.Run("plain and JSON lines", func(t *testing.T) {
+		logs := strings.Join([]string{
+			`2024/05/01 12:00:00 ERROR request failed trace="[handler.go:10] api.Get ➡️ >>> users >>> [client.go:20] users.call ➡️ [server.go:30] users.(*Server).handle"`,
+			`no trace here`,
+			`{"level":"error","err":{"trace":">>> users >>> [internal/db/db.go:5] github.com/acme/users/db.Query"}}`,
+			`{not json [a.go:1] a.f`,
+		}, "\n")
+
+		var out bytes.Buffer
+		if err := run([]string{"trace"}, strings.NewReader(logs), &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := `line 1:
+└─ (this service)
+   [handler.go:10] api.Get
+   └─ users
+      [client.go:20] users.call
+      [server.go:30] users.(*Server).handle
+line 3:
+└─ users
+   [internal/db/db.go:5] github.com/acme/users/db.Query
+line 4:
+└─ (this service)
+   [a.go:1] a.f
+`
+		if out.String() != want {
+			t.Errorf("expected\n%s\ngot\n%s", want, out.String())
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		if err := run([]string{"stack"}, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for unknown command")
+		}
+		if err := run(nil, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+			t.Errorf("expected error without command")
+		}
+	})
+}
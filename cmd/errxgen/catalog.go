@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/code19m/errx"
+	"gopkg.in/yaml.v3"
+)
+
+// catalogFile is the structure of an error catalog file.
+// YAML is a superset of JSON, so both formats are read with the same decoder.
+type catalogFile struct {
+	Package string      `yaml:"package" json:"package"`
+	Codes   []codeEntry `yaml:"codes" json:"codes"`
+}
+
+// codeEntry declares a single error code in the catalog file.
+type codeEntry struct {
+	Code        string   `yaml:"code" json:"code"`
+	Type        string   `yaml:"type" json:"type"`
+	Message     string   `yaml:"message" json:"message"`
+	Description string   `yaml:"description" json:"description"`
+	Owner       string   `yaml:"owner" json:"owner"`
+	Aliases     []string `yaml:"aliases" json:"aliases"`
+	Deprecated  bool     `yaml:"deprecated" json:"deprecated"`
+}
+
+// code is a validated catalog entry, ready for generation.
+type code struct {
+	codeEntry
+
+	// Name is the Go identifier derived from the code, e.g. "UserNotFound".
+	Name string
+
+	// Kind is the parsed error type.
+	Kind errx.Type
+
+	// Params are the message placeholders in order of appearance.
+	Params []param
+}
+
+// param is a message placeholder, e.g. "{user_id}".
+type param struct {
+	// Key is the placeholder name as written in the message.
+	Key string
+
+	// Name is the Go identifier used as constructor parameter, e.g. "userID".
+	Name string
+}
+
+// placeholderRe matches message placeholders like "{user_id}".
+var placeholderRe = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// loadCatalog reads and validates the catalog file at path.
+func loadCatalog(path string) (string, []code, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var file catalogFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return "", nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	codes, err := validate(file.Codes)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Package, codes, nil
+}
+
+// validate checks the catalog entries and derives the generation data.
+func validate(entries []codeEntry) ([]code, error) {
+	codes := make([]code, 0, len(entries))
+	seenCodes := make(map[string]bool)
+	seenNames := make(map[string]string)
+
+	for i, entry := range entries {
+		if entry.Code == "" {
+			return nil, fmt.Errorf("entry %d: code is required", i)
+		}
+		for _, c := range append([]string{entry.Code}, entry.Aliases...) {
+			if seenCodes[c] {
+				return nil, fmt.Errorf("code %q is declared more than once", c)
+			}
+			seenCodes[c] = true
+		}
+
+		entry.Description = strings.TrimSpace(entry.Description)
+		c := code{codeEntry: entry, Name: goName(entry.Code, true), Kind: errx.DefaultType}
+		if other, ok := seenNames[c.Name]; ok {
+			return nil, fmt.Errorf("codes %q and %q produce the same Go name %s", other, entry.Code, c.Name)
+		}
+		seenNames[c.Name] = entry.Code
+
+		if entry.Type != "" {
+			t, ok := parseType(entry.Type)
+			if !ok {
+				return nil, fmt.Errorf("code %q: unknown type %q", entry.Code, entry.Type)
+			}
+			c.Kind = t
+		}
+
+		seenParams := make(map[string]bool)
+		paramNames := make(map[string]string)
+		for _, m := range placeholderRe.FindAllStringSubmatch(entry.Message, -1) {
+			if seenParams[m[1]] {
+				continue
+			}
+			seenParams[m[1]] = true
+
+			p := param{Key: m[1], Name: goName(m[1], false)}
+			if other, ok := paramNames[p.Name]; ok {
+				return nil, fmt.Errorf("code %q: placeholders {%s} and {%s} produce the same Go name %s", entry.Code, other, p.Key, p.Name)
+			}
+			paramNames[p.Name] = p.Key
+			c.Params = append(c.Params, p)
+		}
+
+		codes = append(codes, c)
+	}
+
+	return codes, nil
+}
+
+// parseType parses a type name, with or without the "T_" prefix.
+func parseType(name string) (errx.Type, bool) {
+	if !strings.HasPrefix(name, "T_") {
+		name = "T_" + name
+	}
+	return errx.ParseType(name)
+}
+
+// initialisms are words rendered in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"API": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URL": true, "UUID": true,
+}
+
+// reservedNames are the identifiers used inside the generated constructors,
+// which parameters named after placeholders must not shadow.
+var reservedNames = map[string]bool{
+	"opts": true, "errx": true, "append": true,
+}
+
+// goName converts a code or placeholder like "USER_NOT_FOUND" or "user_id"
+// into a Go identifier like "UserNotFound" or "userID".
+func goName(s string, exported bool) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for i, w := range words {
+		upper := strings.ToUpper(w)
+		switch {
+		case i == 0 && !exported:
+			b.WriteString(strings.ToLower(w))
+		case initialisms[upper]:
+			b.WriteString(upper)
+		default:
+			b.WriteString(upper[:1] + strings.ToLower(w[1:]))
+		}
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "E" + name
+	}
+	if token.IsKeyword(name) || reservedNames[name] {
+		name += "_"
+	}
+	return name
+}
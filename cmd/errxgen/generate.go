@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/code19m/errx"
+)
+
+// generateGo renders the Go source declaring the codes and their constructors.
+func generateGo(pkg, source string, codes []code) ([]byte, error) {
+	var buf bytes.Buffer
+	err := goTmpl.Execute(&buf, struct {
+		Package string
+		Source  string
+		Codes   []code
+	}{pkg, source, codes})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return out, nil
+}
+
+// generateMarkdown renders a Markdown reference table of the codes.
+func generateMarkdown(source string, codes []code) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!-- Code generated by errxgen from %s. DO NOT EDIT. -->\n\n", source)
+	buf.WriteString("# Error codes\n\n")
+	buf.WriteString("| Code | Type | HTTP status | Message | Description | Owner |\n")
+	buf.WriteString("|------|------|-------------|---------|-------------|-------|\n")
+	for _, c := range codes {
+		description := c.Description
+		if len(c.Aliases) > 0 {
+			description += " Aliases: `" + strings.Join(c.Aliases, "`, `") + "`."
+		}
+		if c.Deprecated {
+			description = "**Deprecated.** " + description
+		}
+		fmt.Fprintf(&buf, "| `%s` | `%s` | %d | %s | %s | %s |\n",
+			c.Code, c.Kind, errx.HTTPStatus(c.Kind),
+			markdownCell(c.Message), markdownCell(description), markdownCell(c.Owner),
+		)
+	}
+	return buf.Bytes()
+}
+
+// generateTypeScript renders TypeScript constants for frontend clients.
+func generateTypeScript(source string, codes []code) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by errxgen from %s. DO NOT EDIT.\n\n", source)
+	buf.WriteString("export const ErrorCodes = {\n")
+	for _, c := range codes {
+		fmt.Fprintf(&buf, "  %s: %s,\n", c.Name, strconv.Quote(c.Code))
+	}
+	buf.WriteString("} as const;\n\n")
+	buf.WriteString("export type ErrorCode = (typeof ErrorCodes)[keyof typeof ErrorCodes];\n\n")
+	buf.WriteString("export const ErrorTypes: Record<ErrorCode, string> = {\n")
+	for _, c := range codes {
+		fmt.Fprintf(&buf, "  [ErrorCodes.%s]: %s,\n", c.Name, strconv.Quote(c.Kind.String()))
+	}
+	buf.WriteString("};\n")
+	return buf.Bytes()
+}
+
+// markdownCell escapes a value for use in a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// comment renders a text as Go line comments.
+func comment(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("// "+l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+var goTmpl = template.Must(template.New("go").Funcs(template.FuncMap{
+	"quote":   strconv.Quote,
+	"comment": comment,
+}).Parse(`// Code generated by errxgen from {{ .Source }}. DO NOT EDIT.
+
+package {{ .Package }}
+
+import "github.com/code19m/errx"
+
+// Error codes declared in {{ .Source }}.
+const (
+{{- range .Codes }}
+	Code{{ .Name }} = {{ quote .Code }}
+{{- end }}
+)
+
+func init() {
+	errx.Declare(
+{{- range .Codes }}
+		errx.CodeDef{
+			Code:        Code{{ .Name }},
+			Type:        errx.{{ .Kind }},
+			Message:     {{ quote .Message }},
+			Description: {{ quote .Description }},
+			Owner:       {{ quote .Owner }},
+{{- if .Aliases }}
+			Aliases:     []string{ {{- range $i, $a := .Aliases }}{{ if $i }}, {{ end }}{{ quote $a }}{{ end -}} },
+{{- end }}
+{{- if .Deprecated }}
+			Deprecated:  true,
+{{- end }}
+		},
+{{- end }}
+	)
+}
+{{ range .Codes }}
+// New{{ .Name }} creates a new {{ .Code }} error.
+{{- if .Description }}
+//
+{{ comment .Description }}
+{{- end }}
+{{- if .Deprecated }}
+//
+// Deprecated: the {{ .Code }} code should no longer be produced.
+{{- end }}
+func New{{ .Name }}({{ range .Params }}{{ .Name }} any, {{ end }}opts ...errx.OptionFunc) error {
+	opts = append([]errx.OptionFunc{
+		errx.WithCode(Code{{ .Name }}),
+		errx.WithType(errx.{{ .Kind }}),
+{{- if .Params }}
+		errx.WithTemplate({{ quote .Message }}, errx.P{
+{{- range .Params }}
+			{{ quote .Key }}: {{ .Name }},
+{{- end }}
+		}),
+{{- end }}
+	}, opts...)
+{{- if .Params }}
+	return errx.NewDepth(1, "", opts...)
+{{- else }}
+	return errx.NewDepth(1, {{ quote .Message }}, opts...)
+{{- end }}
+}
+{{ end }}`))
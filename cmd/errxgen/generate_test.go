@@ -0,0 +1,216 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+const testCatalog = `
+package: errs
+codes:
+  - code: USER_NOT_FOUND
+    type: NotFound
+    message: "user {user_id} not found (100%)"
+    description: The requested user does not exist.
+    owner: identity
+  - code: EMAIL_TAKEN
+    type: T_Conflict
+    message: email is already taken
+    aliases: [EMAIL_EXISTS]
+    deprecated: true
+`
+
+func writeCatalog(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCatalog(t *testing.T) {
+	t.Run("load YAML catalog", func(t *testing.T) {
+		pkg, codes, err := loadCatalog(writeCatalog(t, "errors.yaml", testCatalog))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pkg != "errs" || len(codes) != 2 {
+			t.Fatalf("unexpected catalog: %v, %v", pkg, codes)
+		}
+		if codes[0].Name != "UserNotFound" || codes[0].Kind != errx.T_NotFound {
+			t.Errorf("unexpected code: %+v", codes[0])
+		}
+		if len(codes[0].Params) != 1 || codes[0].Params[0].Name != "userID" {
+			t.Errorf("unexpected params: %+v", codes[0].Params)
+		}
+	})
+
+	t.Run("load JSON catalog", func(t *testing.T) {
+		path := writeCatalog(t, "errors.json", `{"package":"errs","codes":[{"code":"TOO_BIG","type":"T_TooLarge"}]}`)
+		_, codes, err := loadCatalog(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(codes) != 1 || codes[0].Kind != errx.T_TooLarge {
+			t.Errorf("unexpected codes: %+v", codes)
+		}
+	})
+
+	t.Run("reject invalid catalogs", func(t *testing.T) {
+		testCases := map[string]string{
+			"unknown type":    `codes: [{code: A, type: T_Nope}]`,
+			"duplicate code":  `codes: [{code: A}, {code: A}]`,
+			"alias of code":   `codes: [{code: A}, {code: B, aliases: [A]}]`,
+			"missing code":    `codes: [{type: T_Internal}]`,
+			"name collision":  `codes: [{code: USER_GONE}, {code: user.gone}]`,
+			"param collision": `codes: [{code: A, message: "{opts} {opts_}"}]`,
+		}
+		for name, content := range testCases {
+			if _, _, err := loadCatalog(writeCatalog(t, "errors.yaml", content)); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestGenerateGo(t *testing.T) {
+	_, codes, err := loadCatalog(writeCatalog(t, "errors.yaml", testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateGo("errs", "errors.yaml", codes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "errors_gen.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+
+	for _, want := range []string{
+		`CodeUserNotFound = "USER_NOT_FOUND"`,
+		`func NewUserNotFound(userID any, opts ...errx.OptionFunc) error`,
+		`errx.WithTemplate("user {user_id} not found (100%)", errx.P{`,
+		`"user_id": userID,`,
+		`return errx.NewDepth(1, "", opts...)`,
+		`errx.WithCode(CodeEmailTaken),`,
+		`errx.WithType(errx.T_Conflict),`,
+		`Message:     "email is already taken"`,
+		`Aliases:     []string{"EMAIL_EXISTS"}`,
+		`Deprecated:  true`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected generated code to contain %q\n%s", want, src)
+		}
+	}
+}
+
+func TestGeneratedTrace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, codes, err := loadCatalog(writeCatalog(t, "errors.yaml", testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generateGo("main", "errors.yaml", codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":        "module gentest\n\ngo 1.23.1\n\nrequire github.com/code19m/errx v0.0.0\n\nreplace github.com/code19m/errx => " + root + "\n",
+		"errors_gen.go": string(src),
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/code19m/errx"
+)
+
+func callSite() error {
+	return NewUserNotFound(42)
+}
+
+func main() {
+	fmt.Print(errx.GetFrames(callSite())[0])
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goCmd, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run generated code: %v\n%s", err, out)
+	}
+	if got := string(out); !strings.HasPrefix(got, "[main.go:") || !strings.HasSuffix(got, "main.callSite") {
+		t.Errorf("expected the trace to start at the caller of the constructor, got %q", got)
+	}
+}
+
+func TestGenerateDocs(t *testing.T) {
+	_, codes, err := loadCatalog(writeCatalog(t, "errors.yaml", testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := string(generateMarkdown("errors.yaml", codes))
+	if !strings.Contains(md, "| `USER_NOT_FOUND` | `T_NotFound` | 404 |") {
+		t.Errorf("unexpected Markdown:\n%s", md)
+	}
+
+	ts := string(generateTypeScript("errors.yaml", codes))
+	if !strings.Contains(ts, `UserNotFound: "USER_NOT_FOUND",`) || !strings.Contains(ts, `[ErrorCodes.EmailTaken]: "T_Conflict",`) {
+		t.Errorf("unexpected TypeScript:\n%s", ts)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	testCases := []struct {
+		in       string
+		exported bool
+		expected string
+	}{
+		{"USER_NOT_FOUND", true, "UserNotFound"},
+		{"billing.invoice.NOT_FOUND", true, "BillingInvoiceNotFound"},
+		{"user_id", false, "userID"},
+		{"api_url", false, "apiURL"},
+		{"type", false, "type_"},
+		{"errx", false, "errx_"},
+		{"opts", false, "opts_"},
+		{"append", false, "append_"},
+		{"2FA_REQUIRED", true, "E2faRequired"},
+	}
+
+	for _, tc := range testCases {
+		if got := goName(tc.in, tc.exported); got != tc.expected {
+			t.Errorf("goName(%q) = %q, want %q", tc.in, got, tc.expected)
+		}
+	}
+}
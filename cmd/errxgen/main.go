@@ -0,0 +1,90 @@
+// Command errxgen generates Go constructors, a Markdown reference and
+// frontend constants from an error catalog file.
+//
+// The catalog is a YAML or JSON file:
+//
+//	package: errs
+//	codes:
+//	  - code: USER_NOT_FOUND
+//	    type: T_NotFound
+//	    message: "user {user_id} not found"
+//	    description: The requested user does not exist.
+//	    owner: identity
+//
+// For each code, errxgen generates a CodeXxx constant, a catalog declaration
+// (see errx.Declare) and a NewXxx constructor taking one parameter per message placeholder.
+// The constructors use errx.New, errx.WithCode, errx.WithType and errx.WithTemplate,
+// so the catalog, the code and the docs can never drift apart.
+//
+// Usage:
+//
+//	//go:generate go run github.com/code19m/errx/cmd/errxgen -in errors.yaml -go errors_gen.go -md ERRORS.md -ts errors.ts
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	var (
+		in    = flag.String("in", "", "path of the error catalog file (YAML or JSON)")
+		goOut = flag.String("go", "", "path of the generated Go file")
+		pkg   = flag.String("pkg", "", "package name of the generated Go file (overrides the catalog)")
+		mdOut = flag.String("md", "", "path of the generated Markdown reference (optional)")
+		tsOut = flag.String("ts", "", "path of the generated TypeScript constants (optional)")
+	)
+	flag.Parse()
+
+	if err := run(*in, *goOut, *pkg, *mdOut, *tsOut); err != nil {
+		fmt.Fprintln(os.Stderr, "errxgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, goOut, pkg, mdOut, tsOut string) error {
+	if in == "" {
+		return fmt.Errorf("-in is required")
+	}
+	if goOut == "" && mdOut == "" && tsOut == "" {
+		return fmt.Errorf("at least one of -go, -md and -ts is required")
+	}
+
+	filePkg, codes, err := loadCatalog(in)
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = filePkg
+	}
+	source := filepath.Base(in)
+
+	if goOut != "" {
+		if pkg == "" {
+			return fmt.Errorf("package name is required, set it in the catalog or with -pkg")
+		}
+		src, err := generateGo(pkg, source, codes)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(goOut, src, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if mdOut != "" {
+		if err := os.WriteFile(mdOut, generateMarkdown(source, codes), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if tsOut != "" {
+		if err := os.WriteFile(tsOut, generateTypeScript(source, codes), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
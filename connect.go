@@ -0,0 +1,270 @@
+package errx
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/code19m/errx/internal/errorx_proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConnectError is the JSON body of a Connect protocol error response.
+type ConnectError struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message,omitempty"`
+	Details []ConnectDetail `json:"details,omitempty"`
+}
+
+// ConnectDetail is a single entry of the details of a Connect error.
+// Value holds the base64 encoded protobuf message named by Type.
+type ConnectDetail struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Debug any    `json:"debug,omitempty"`
+}
+
+// Error implements the error interface.
+func (ce *ConnectError) Error() string {
+	return ce.Message
+}
+
+// TwirpError is the JSON body of a Twirp error response.
+type TwirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// Error implements the error interface.
+func (te *TwirpError) Error() string {
+	return te.Msg
+}
+
+// Keys of the Twirp meta entries holding ErrorX information.
+// Validation fields and template parameters are stored under TwirpMetaFieldPrefix
+// and TwirpMetaParamPrefix followed by the field or parameter name.
+const (
+	TwirpMetaCode        = "errx_code"
+	TwirpMetaType        = "errx_type"
+	TwirpMetaTrace       = "errx_trace"
+	TwirpMetaTemplate    = "errx_template"
+	TwirpMetaFieldPrefix = "errx_field."
+	TwirpMetaParamPrefix = "errx_param."
+)
+
+// ToConnectError converts an error into a Connect error body.
+//
+// The code is derived from the error type using the same mapping as ToGRPCError.
+// The ErrorX is carried as a protobuf detail, so Connect clients using the errx proto can read it.
+// If the provided error does not implement the ErrorX interface, it is wrapped
+// into a default ErrorX instance.
+//
+// If the error is nil, nil is returned.
+func ToConnectError(err error) *ConnectError {
+	if err == nil {
+		return nil
+	}
+
+	e := errorXOf(err)
+
+	ce := &ConnectError{
+		Code:    protocolCodeName(mapErrorToGRPCCode(e)),
+		Message: e.PublicMessage(),
+	}
+
+	pb := toProto(e)
+	if value, merr := proto.Marshal(pb); merr == nil {
+		ce.Details = []ConnectDetail{{
+			Type:  string(pb.ProtoReflect().Descriptor().FullName()),
+			Value: base64.RawStdEncoding.EncodeToString(value),
+		}}
+	}
+
+	return ce
+}
+
+// FromConnectError converts a Connect error body into a custom error (ErrorX).
+//
+// It is intended for use on the client side after making Connect calls.
+// The function returns a boolean indicating whether the error was converted from an ErrorX detail (`true`) or not (`false`).
+// If no ErrorX detail is present, the type is derived from the code.
+//
+// If the error body is nil, no action is taken, and the function returns `false, nil`.
+// Optional modifications can be applied via OptionFunc.
+func FromConnectError(ce *ConnectError, opts ...OptionFunc) (bool, error) {
+	if ce == nil {
+		return false, nil
+	}
+
+	name := string((&errorx_proto.ErrorX{}).ProtoReflect().Descriptor().FullName())
+	for _, detail := range ce.Details {
+		if detail.Type != name {
+			continue
+		}
+		value, derr := decodeBase64(detail.Value)
+		if derr != nil {
+			continue
+		}
+		var pb errorx_proto.ErrorX
+		if proto.Unmarshal(value, &pb) == nil {
+			e := fromProto(&pb)
+			e.addTrace(2)
+			applyOpts(e, opts)
+			return true, e
+		}
+	}
+
+	e := newFromProtocolCode(ce.Code, ce.Message)
+	e.addTrace(2)
+	applyOpts(e, opts)
+	return false, e
+}
+
+// ToTwirpError converts an error into a Twirp error body.
+//
+// The code is derived from the error type using the same mapping as ToGRPCError.
+// The error code, type name, trace, validation fields, and the message template
+// with its parameters are carried in the meta section
+// (see TwirpMetaCode and related constants).
+// If the provided error does not implement the ErrorX interface, it is wrapped
+// into a default ErrorX instance.
+//
+// If the error is nil, nil is returned.
+func ToTwirpError(err error) *TwirpError {
+	if err == nil {
+		return nil
+	}
+
+	e := errorXOf(err)
+
+	meta := map[string]string{
+		TwirpMetaCode: e.Code(),
+		TwirpMetaType: e.Type().String(),
+	}
+	if trace := (TraceRenderer{}).Render(e); trace != "" {
+		meta[TwirpMetaTrace] = trace
+	}
+	template, params := e.publicTemplate()
+	if template != "" {
+		meta[TwirpMetaTemplate] = template
+	}
+	for k, v := range e.Fields() {
+		meta[TwirpMetaFieldPrefix+k] = v
+	}
+	for k, v := range stringParams(params) {
+		meta[TwirpMetaParamPrefix+k] = v
+	}
+
+	return &TwirpError{
+		Code: twirpCodeName(mapErrorToGRPCCode(e)),
+		Msg:  e.PublicMessage(),
+		Meta: meta,
+	}
+}
+
+// FromTwirpError converts a Twirp error body into a custom error (ErrorX).
+//
+// It is intended for use on the client side after making Twirp calls.
+// The function returns a boolean indicating whether the error was converted from ErrorX meta entries (`true`) or not (`false`).
+// If no ErrorX meta entries are present, the type is derived from the code.
+//
+// If the error body is nil, no action is taken, and the function returns `false, nil`.
+// Optional modifications can be applied via OptionFunc.
+func FromTwirpError(te *TwirpError, opts ...OptionFunc) (bool, error) {
+	if te == nil {
+		return false, nil
+	}
+
+	e := newFromProtocolCode(te.Code, te.Msg)
+
+	code, ok := te.Meta[TwirpMetaCode]
+	if ok {
+		e.code = CanonicalCode(code)
+		if t, found := ParseType(te.Meta[TwirpMetaType]); found {
+			e.type_ = t
+		}
+		e.callers, e.rawTrace = receivedTrace(te.Meta[TwirpMetaTrace], nil)
+		e.template = te.Meta[TwirpMetaTemplate]
+		for k, v := range te.Meta {
+			if name, found := strings.CutPrefix(k, TwirpMetaFieldPrefix); found {
+				e.fields[name] = v
+			}
+			if name, found := strings.CutPrefix(k, TwirpMetaParamPrefix); found {
+				if e.params == nil {
+					e.params = make(P)
+				}
+				e.params[name] = v
+			}
+		}
+	}
+
+	e.addTrace(2)
+	applyOpts(e, opts)
+	return ok, e
+}
+
+// newFromProtocolCode creates a new ErrorX from a lowercase Connect or Twirp code.
+func newFromProtocolCode(code, msg string) *errorX {
+	if code == "dataloss" {
+		code = "data_loss"
+	}
+
+	e := newDefault(msg)
+	for c, name := range protocolCodeNames {
+		if name != code {
+			continue
+		}
+		if t, ok := grpcCodeTypes[c]; ok {
+			e.type_ = t
+		}
+		break
+	}
+	return e
+}
+
+// protocolCodeNames maps gRPC codes to the lowercase code strings used by Connect and Twirp.
+var protocolCodeNames = map[codes.Code]string{
+	codes.Canceled:           "canceled",
+	codes.Unknown:            "unknown",
+	codes.InvalidArgument:    "invalid_argument",
+	codes.DeadlineExceeded:   "deadline_exceeded",
+	codes.NotFound:           "not_found",
+	codes.AlreadyExists:      "already_exists",
+	codes.PermissionDenied:   "permission_denied",
+	codes.ResourceExhausted:  "resource_exhausted",
+	codes.FailedPrecondition: "failed_precondition",
+	codes.Aborted:            "aborted",
+	codes.OutOfRange:         "out_of_range",
+	codes.Unimplemented:      "unimplemented",
+	codes.Internal:           "internal",
+	codes.Unavailable:        "unavailable",
+	codes.DataLoss:           "data_loss",
+	codes.Unauthenticated:    "unauthenticated",
+}
+
+// protocolCodeName returns the Connect code string for a gRPC code.
+func protocolCodeName(c codes.Code) string {
+	if name, ok := protocolCodeNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// twirpCodeName returns the Twirp code string for a gRPC code.
+// Twirp uses the same names as Connect, except for "dataloss".
+func twirpCodeName(c codes.Code) string {
+	if c == codes.DataLoss {
+		return "dataloss"
+	}
+	return protocolCodeName(c)
+}
+
+// decodeBase64 decodes standard base64 with or without padding,
+// as Connect allows both.
+func decodeBase64(s string) ([]byte, error) {
+	if strings.HasSuffix(s, "=") {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
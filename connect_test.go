@@ -0,0 +1,154 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestToConnectError(t *testing.T) {
+	t.Run("convert types to codes", func(t *testing.T) {
+		testCases := []struct {
+			errType  errx.Type
+			expected string
+		}{
+			{errx.T_Internal, "internal"},
+			{errx.T_Validation, "invalid_argument"},
+			{errx.T_NotFound, "not_found"},
+			{errx.T_Conflict, "already_exists"},
+			{errx.T_Authentication, "unauthenticated"},
+			{errx.T_Forbidden, "permission_denied"},
+			{errx.Type(99), "unknown"},
+		}
+
+		for _, tc := range testCases {
+			ce := errx.ToConnectError(errx.New("test error", errx.WithType(tc.errType)))
+			if ce.Code != tc.expected {
+				t.Errorf("for error type %v, expected code %v, got %v", tc.errType, tc.expected, ce.Code)
+			}
+		}
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		if ce := errx.ToConnectError(nil); ce != nil {
+			t.Errorf("expected nil, got %v", ce)
+		}
+	})
+}
+
+func TestFromConnectError(t *testing.T) {
+	t.Run("round trip through JSON", func(t *testing.T) {
+		src := errx.New("invalid input",
+			errx.WithCode("INVALID_INPUT"),
+			errx.WithType(errx.T_Validation),
+			errx.WithFields(errx.M{"email": "invalid format"}),
+		)
+		data, _ := json.Marshal(errx.ToConnectError(src))
+
+		var ce errx.ConnectError
+		if err := json.Unmarshal(data, &ce); err != nil {
+			t.Fatalf("unexpected unmarshal error: %v", err)
+		}
+
+		ok, err := errx.FromConnectError(&ce)
+		if !ok {
+			t.Fatalf("expected successful conversion")
+		}
+		e := err.(errx.ErrorX)
+		if e.Code() != "INVALID_INPUT" || e.Type() != errx.T_Validation {
+			t.Errorf("unexpected code or type: %v, %v", e.Code(), e.Type())
+		}
+		if e.Fields()["email"] != "invalid format" {
+			t.Errorf("unexpected fields: %v", e.Fields())
+		}
+		if !contains(e.Trace(), "connect_test.go") {
+			t.Errorf("expected trace to be carried, got: %v", e.Trace())
+		}
+	})
+
+	t.Run("foreign error uses code", func(t *testing.T) {
+		ok, err := errx.FromConnectError(&errx.ConnectError{Code: "not_found", Message: "missing"})
+		if ok {
+			t.Errorf("expected unsuccessful conversion")
+		}
+		if errx.GetType(err) != errx.T_NotFound || err.Error() != "missing" {
+			t.Errorf("unexpected error: %v, %v", errx.GetType(err), err)
+		}
+	})
+
+	t.Run("nil error body", func(t *testing.T) {
+		ok, err := errx.FromConnectError(nil)
+		if ok || err != nil {
+			t.Errorf("expected false, nil; got %v, %v", ok, err)
+		}
+	})
+}
+
+func TestToTwirpError(t *testing.T) {
+	t.Run("carry ErrorX in meta", func(t *testing.T) {
+		te := errx.ToTwirpError(errx.New("forbidden", errx.WithCode("NO_ACCESS"), errx.WithType(errx.T_Forbidden)))
+		if te.Code != "permission_denied" || te.Msg != "Permission denied" {
+			t.Errorf("unexpected error body: %+v", te)
+		}
+		if te.Meta[errx.TwirpMetaCode] != "NO_ACCESS" || te.Meta[errx.TwirpMetaType] != "T_Forbidden" {
+			t.Errorf("unexpected meta: %v", te.Meta)
+		}
+	})
+
+	t.Run("regular error", func(t *testing.T) {
+		te := errx.ToTwirpError(errors.New("regular error"))
+		if te.Code != "internal" {
+			t.Errorf("expected internal code, got %v", te.Code)
+		}
+	})
+}
+
+func TestFromTwirpError(t *testing.T) {
+	t.Run("round trip through JSON", func(t *testing.T) {
+		src := errx.New("invalid input",
+			errx.WithCode("INVALID_INPUT"),
+			errx.WithType(errx.T_Validation),
+			errx.WithFields(errx.M{"email": "invalid format"}),
+		)
+		data, _ := json.Marshal(errx.ToTwirpError(src))
+
+		var te errx.TwirpError
+		if err := json.Unmarshal(data, &te); err != nil {
+			t.Fatalf("unexpected unmarshal error: %v", err)
+		}
+
+		ok, err := errx.FromTwirpError(&te, errx.WithTracePrefix("users"))
+		if !ok {
+			t.Fatalf("expected successful conversion")
+		}
+		e := err.(errx.ErrorX)
+		if e.Code() != "INVALID_INPUT" || e.Type() != errx.T_Validation {
+			t.Errorf("unexpected code or type: %v, %v", e.Code(), e.Type())
+		}
+		if e.Fields()["email"] != "invalid format" {
+			t.Errorf("unexpected fields: %v", e.Fields())
+		}
+		if !contains(e.Trace(), ">>> users >>>") || !contains(e.Trace(), "connect_test.go") {
+			t.Errorf("unexpected trace: %v", e.Trace())
+		}
+	})
+
+	t.Run("foreign error uses code", func(t *testing.T) {
+		ok, err := errx.FromTwirpError(&errx.TwirpError{Code: "unauthenticated", Msg: "login required"})
+		if ok {
+			t.Errorf("expected unsuccessful conversion")
+		}
+		if errx.GetType(err) != errx.T_Authentication {
+			t.Errorf("expected type T_Authentication, got %v", errx.GetType(err))
+		}
+	})
+
+	t.Run("nil error body", func(t *testing.T) {
+		ok, err := errx.FromTwirpError(nil)
+		if ok || err != nil {
+			t.Errorf("expected false, nil; got %v, %v", ok, err)
+		}
+	})
+}
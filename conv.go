@@ -3,10 +3,15 @@ package errx
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/code19m/errx/internal/errorx_proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 // ToGRPCError converts a custom error (ErrorX) into a gRPC-compatible error.
@@ -25,30 +30,17 @@ func ToGRPCError(err error, opts ...OptionFunc) error {
 		return nil
 	}
 
-	e, ok := err.(*errorX)
-	if !ok {
-		e = newDefault(err.Error())
-	}
+	e := errorXOf(err)
 
 	// Clone the error to avoid modifying the original
 	e = e.clone()
 
 	// Apply options
 	e.addTrace(2)
+	e.addHop(HopSend)
 	applyOpts(e, opts)
 
-	st, derr := status.New(mapErrorToGRPCCode(e), e.Error()).WithDetails(toProto(e))
-	if derr != nil {
-		return New(
-			fmt.Sprintf(
-				"Failed to create grpc status object with details: %s. Original error was: %s",
-				derr.Error(),
-				e.Error(),
-			),
-		)
-	}
-
-	return st.Err()
+	return toGRPCStatusError(e)
 }
 
 // FromGRPCError converts a gRPC error into a custom error (ErrorX).
@@ -74,9 +66,12 @@ func FromGRPCError(err error, opts ...OptionFunc) (bool, error) {
 		return false, e
 	}
 
+	loc := localizedFromDetails(st.Details())
+
 	for _, detail := range st.Details() {
 		if pb, ok := detail.(*errorx_proto.ErrorX); ok {
 			e := fromProto(pb)
+			e.localized = loc
 			e.addTrace(2)
 			applyOpts(e, opts)
 			return true, e
@@ -84,38 +79,184 @@ func FromGRPCError(err error, opts ...OptionFunc) (bool, error) {
 	}
 
 	e := newFromStatus(st)
+	e.localized = loc
 	e.addTrace(2)
 	applyOpts(e, opts)
 	return false, e
 }
 
+// localizedFromDetails reads the translation added by toGRPCStatusError from the status details.
+func localizedFromDetails(details []any) *localizedMessage {
+	var loc *localizedMessage
+	for _, detail := range details {
+		if lm, ok := detail.(*errdetails.LocalizedMessage); ok {
+			loc = &localizedMessage{locale: lm.GetLocale(), message: lm.GetMessage()}
+		}
+	}
+	if loc == nil {
+		return nil
+	}
+
+	for _, detail := range details {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			loc.fields = make(M)
+			for _, fv := range br.GetFieldViolations() {
+				loc.fields[fv.GetField()] = fv.GetDescription()
+			}
+		}
+	}
+	return loc
+}
+
+// toGRPCStatusError creates a gRPC status error holding the ErrorX in its details.
+// A translation attached by a Localizer is added as google.rpc.LocalizedMessage,
+// and its translated fields as google.rpc.BadRequest field violations.
+func toGRPCStatusError(e *errorX) error {
+	details := []protoadapt.MessageV1{toProto(e)}
+	if loc := e.localized; loc != nil {
+		details = append(details, &errdetails.LocalizedMessage{Locale: loc.locale, Message: loc.message})
+
+		if len(loc.fields) > 0 {
+			br := &errdetails.BadRequest{}
+			for _, field := range slices.Sorted(maps.Keys(loc.fields)) {
+				br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+					Field:       field,
+					Description: loc.fields[field],
+				})
+			}
+			details = append(details, br)
+		}
+	}
+
+	st, derr := status.New(mapErrorToGRPCCode(e), e.PublicMessage()).WithDetails(details...)
+	if derr != nil {
+		return New(
+			fmt.Sprintf(
+				"Failed to create grpc status object with details: %s. Original error was: %s",
+				derr.Error(),
+				e.Error(),
+			),
+		)
+	}
+
+	return st.Err()
+}
+
 // fromProto converts a proto error to an ErrorX.
 func fromProto(pbErr *errorx_proto.ErrorX) *errorX {
-	return &errorX{
-		code:    pbErr.GetCode(),
-		msg:     pbErr.GetMessage(),
-		type_:   Type(pbErr.GetType()),
-		fields:  M(pbErr.GetFields()),
-		details: make(D),
-		trace:   pbErr.GetTrace(),
-		origin:  errors.New(pbErr.GetMessage()),
+	var params P
+	if len(pbErr.GetParams()) > 0 {
+		params = make(P, len(pbErr.GetParams()))
+		for k, v := range pbErr.GetParams() {
+			params[k] = v
+		}
+	}
+
+	frames := make([]Frame, len(pbErr.GetFrames()))
+	for i, f := range pbErr.GetFrames() {
+		frames[i] = Frame{
+			File:     f.GetFile(),
+			Path:     f.GetPath(),
+			Line:     int(f.GetLine()),
+			Function: f.GetFunction(),
+			Package:  f.GetPackage(),
+			Service:  f.GetService(),
+			Repeat:   int(f.GetRepeat()),
+			Omitted:  int(f.GetOmitted()),
+		}
+	}
+
+	callers, rawTrace := receivedTrace(pbErr.GetTrace(), frames)
+
+	var created time.Time
+	if ns := pbErr.GetCreatedUnixNano(); ns != 0 {
+		created = time.Unix(0, ns)
+	}
+	hops := make([]Hop, 0, len(pbErr.GetHops())+1)
+	for _, h := range pbErr.GetHops() {
+		hops = append(hops, Hop{Kind: HopKind(h.GetKind()), Time: time.Unix(0, h.GetTimeUnixNano())})
+	}
+
+	e := &errorX{
+		code:     CanonicalCode(pbErr.GetCode()),
+		msg:      pbErr.GetMessage(),
+		template: pbErr.GetTemplate(),
+		params:   params,
+		type_:    Type(pbErr.GetType()),
+		fields:   M(pbErr.GetFields()),
+		details:  make(D),
+		callers:  callers,
+		origin:   errors.New(pbErr.GetMessage()),
+		rawTrace: rawTrace,
+		created:  created,
+		hops:     hops,
+
+		retryable: pbErr.Retryable,
+		temporary: pbErr.Temporary,
+		traceID:   pbErr.GetTraceId(),
+		spanID:    pbErr.GetSpanId(),
 	}
+	e.addHop(HopReceive)
+	return e
 }
 
 // toProto converts an ErrorX to a proto error.
+// The public message is sent instead of the internal one.
+// The trace is sent both rendered in the default format, for peers that only read the trace string,
+// and as structured frames. Times are sent as Unix nanoseconds.
 func toProto(e *errorX) *errorx_proto.ErrorX {
-	return &errorx_proto.ErrorX{
-		Code:    e.Code(),
-		Message: e.Error(),
-		Type:    int32(e.Type()),
-		Fields:  e.Fields(),
-		Trace:   e.Trace(),
+	template, params := e.publicTemplate()
+
+	resolved := e.Frames()
+	frames := make([]*errorx_proto.Frame, len(resolved))
+	for i, f := range resolved {
+		frames[i] = &errorx_proto.Frame{
+			File:     f.File,
+			Path:     f.Path,
+			Line:     int32(f.Line),
+			Function: f.Function,
+			Package:  f.Package,
+			Service:  f.Service,
+			Repeat:   int32(f.Repeat),
+			Omitted:  int32(f.Omitted),
+		}
+	}
+
+	hops := make([]*errorx_proto.Hop, len(e.hops))
+	for i, h := range e.hops {
+		hops[i] = &errorx_proto.Hop{Kind: string(h.Kind), TimeUnixNano: h.Time.UnixNano()}
+	}
+
+	pb := &errorx_proto.ErrorX{
+		Code:     e.Code(),
+		Message:  e.PublicMessage(),
+		Type:     int32(e.Type()),
+		Fields:   e.Fields(),
+		Trace:    TraceRenderer{}.render(resolved, e.rawTrace),
+		Template: template,
+		Params:   stringParams(params),
+		Frames:   frames,
+		Hops:     hops,
+
+		Retryable: e.retryable,
+		Temporary: e.temporary,
+		TraceId:   e.traceID,
+		SpanId:    e.spanID,
+	}
+	if !e.created.IsZero() {
+		pb.CreatedUnixNano = e.created.UnixNano()
 	}
+	return pb
 }
 
 // mapErrorToGRPCCode returns the gRPC code for an ErrorX based on its type.
 func mapErrorToGRPCCode(err *errorX) codes.Code {
-	switch err.Type() {
+	return mapTypeToGRPCCode(err.Type())
+}
+
+// mapTypeToGRPCCode returns the gRPC code for an error type.
+func mapTypeToGRPCCode(t Type) codes.Code {
+	switch t {
 	case T_Internal:
 		return codes.Internal
 	case T_Validation:
@@ -128,6 +269,23 @@ func mapErrorToGRPCCode(err *errorX) codes.Code {
 		return codes.Unauthenticated
 	case T_Forbidden:
 		return codes.PermissionDenied
+	case T_Throttling:
+		return codes.ResourceExhausted
+	case T_Unavailable:
+		return codes.Unavailable
+	case T_Timeout:
+		return codes.DeadlineExceeded
+	case T_Canceled:
+		return codes.Canceled
+	case T_PreconditionFailed:
+		return codes.FailedPrecondition
+	case T_Unimplemented:
+		return codes.Unimplemented
+	case T_TooLarge:
+		// gRPC has no dedicated code for oversized payloads, OutOfRange is the closest one.
+		// It is not mapped back, as peers also use it for ordinary range errors,
+		// such as paging past the end. The type survives the round trip in the ErrorX detail.
+		return codes.OutOfRange
 	}
 	return codes.Unknown
 }
@@ -135,24 +293,33 @@ func mapErrorToGRPCCode(err *errorX) codes.Code {
 // newFromStatus creates a new ErrorX from a gRPC status.
 // This function is used when the gRPC status does not contain an ErrorX in its details.
 func newFromStatus(st *status.Status) *errorX {
-	rpcCodeMap := map[codes.Code]Type{
-		codes.Internal:         T_Internal,
-		codes.InvalidArgument:  T_Validation,
-		codes.NotFound:         T_NotFound,
-		codes.AlreadyExists:    T_Conflict,
-		codes.Unauthenticated:  T_Authentication,
-		codes.PermissionDenied: T_Forbidden,
-	}
-
-	if t, ok := rpcCodeMap[st.Code()]; ok {
+	if t, ok := grpcCodeTypes[st.Code()]; ok {
 		return &errorX{
 			code:    DefaultCode,
 			msg:     st.Message(),
 			type_:   t,
 			fields:  make(M),
 			details: make(D),
+			created: clockNow(),
 		}
 	}
 
 	return newDefault(st.String())
 }
+
+// grpcCodeTypes maps gRPC codes to error types.
+// It is the reverse of mapTypeToGRPCCode, except for OutOfRange, which is left to the default type.
+var grpcCodeTypes = map[codes.Code]Type{
+	codes.Internal:           T_Internal,
+	codes.InvalidArgument:    T_Validation,
+	codes.NotFound:           T_NotFound,
+	codes.AlreadyExists:      T_Conflict,
+	codes.Unauthenticated:    T_Authentication,
+	codes.PermissionDenied:   T_Forbidden,
+	codes.ResourceExhausted:  T_Throttling,
+	codes.Unavailable:        T_Unavailable,
+	codes.DeadlineExceeded:   T_Timeout,
+	codes.Canceled:           T_Canceled,
+	codes.FailedPrecondition: T_PreconditionFailed,
+	codes.Unimplemented:      T_Unimplemented,
+}
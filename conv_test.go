@@ -17,7 +17,7 @@ func TestToGRPCError(t *testing.T) {
 		if !ok {
 			t.Errorf("expected GRPC status error")
 		}
-		if st.Message() != "internal server error" {
+		if st.Message() != "Internal server error" {
 			t.Errorf("unexpected GRPC error message: %v", st.Message())
 		}
 	})
@@ -35,7 +35,7 @@ func TestToGRPCError(t *testing.T) {
 		if !ok {
 			t.Errorf("expected GRPC status error")
 		}
-		if st.Message() != "regular error" {
+		if st.Message() != "Internal server error" {
 			t.Errorf("unexpected GRPC error message: %v", st.Message())
 		}
 	})
@@ -72,6 +72,13 @@ func TestToGRPCError(t *testing.T) {
 			{errx.T_Conflict, codes.AlreadyExists},
 			{errx.T_Authentication, codes.Unauthenticated},
 			{errx.T_Forbidden, codes.PermissionDenied},
+			{errx.T_Throttling, codes.ResourceExhausted},
+			{errx.T_Unavailable, codes.Unavailable},
+			{errx.T_Timeout, codes.DeadlineExceeded},
+			{errx.T_Canceled, codes.Canceled},
+			{errx.T_PreconditionFailed, codes.FailedPrecondition},
+			{errx.T_Unimplemented, codes.Unimplemented},
+			{errx.T_TooLarge, codes.OutOfRange},
 			{errx.Type(99), codes.Unknown}, // Unknown type should map to unknown code
 		}
 
@@ -134,7 +141,14 @@ func TestFromGRPCError(t *testing.T) {
 			{codes.AlreadyExists, errx.T_Conflict},
 			{codes.Unauthenticated, errx.T_Authentication},
 			{codes.PermissionDenied, errx.T_Forbidden},
-			{codes.Unknown, errx.T_Internal}, // Unknown should map to Internal
+			{codes.ResourceExhausted, errx.T_Throttling},
+			{codes.Unavailable, errx.T_Unavailable},
+			{codes.DeadlineExceeded, errx.T_Timeout},
+			{codes.Canceled, errx.T_Canceled},
+			{codes.FailedPrecondition, errx.T_PreconditionFailed},
+			{codes.Unimplemented, errx.T_Unimplemented},
+			{codes.OutOfRange, errx.T_Internal}, // OutOfRange is not specific to oversized payloads
+			{codes.Unknown, errx.T_Internal},    // Unknown should map to Internal
 		}
 
 		for _, tc := range testCases {
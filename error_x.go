@@ -1,18 +1,25 @@
 package errx
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
+	"time"
 )
 
 // ErrorX represents a main interface of this package.
 // It extends the built-in error interface with additional methods
 // to provide structured error information and facilitate debugging.
+//
+// Further information is read with package functions, such as GetPublicMessage, GetFrames,
+// GetStack, GetCreated, GetHops, GetTemplate and GetParams.
 type ErrorX interface {
 
 	// Error returns a human-readable description of the error.
 	// It implements the standard error interface.
+	// The message is intended for logging and may contain internal details.
 	Error() string
 
 	// Code returns a machine-readable error code.
@@ -48,6 +55,7 @@ func New(msg string, opts ...OptionFunc) error {
 	// Apply options
 	e.addTrace(2)
 	applyOpts(e, opts)
+	e.captureStack(2)
 
 	return e
 }
@@ -56,6 +64,24 @@ func New(msg string, opts ...OptionFunc) error {
 func Newf(format string, a ...any) error {
 	e := newDefault(fmt.Sprintf(format, a...))
 	e.addTrace(2)
+	e.settleTrace()
+	e.captureStack(2)
+	return e
+}
+
+// NewDepth creates a new ErrorX like New, recording the call site skip frames above the caller of NewDepth.
+// It is intended for constructor helpers, so that the trace and stack start at the code calling the helper:
+//
+//	func NewUserNotFound(id string) error {
+//	    return errx.NewDepth(1, "user not found", errx.WithCode("USER_NOT_FOUND"))
+//	}
+//
+// A skip of zero behaves like New.
+func NewDepth(skip int, msg string, opts ...OptionFunc) error {
+	e := newDefault(msg)
+	e.addTrace(skip + 2)
+	applyOpts(e, opts)
+	e.captureStack(skip + 2)
 	return e
 }
 
@@ -80,23 +106,65 @@ func Wrap(err error, opts ...OptionFunc) error {
 
 	// Apply options
 	e.addTrace(2)
+	e.addHop(HopWrap)
 	applyOpts(e, opts)
+	e.captureStack(2)
 
 	return e
 }
 
 // errorX is a concrete implementation of the ErrorX interface.
 type errorX struct {
-	code    string
-	msg     string
-	type_   Type
-	fields  M
-	details D
-	trace   string
-	origin  error
+	code      string
+	msg       string
+	publicMsg string
+	template  string
+	params    P
+	type_     Type
+	fields    M
+	details   D
+	origin    error
+
+	// callers are the call sites recorded in the trace, the oldest first.
+	callers []caller
+
+	// rawTrace is a trace received as a string from a peer that did not send structured frames.
+	// It is rendered after the call sites.
+	rawTrace string
+
+	// stack is the call stack captured at creation, if enabled.
+	stack Stack
+
+	// created is the creation time, hops are the steps after it, the oldest first.
+	created time.Time
+	hops    []Hop
+
+	// retryable and temporary override the defaults of the error type when set.
+	retryable *bool
+	temporary *bool
+
+	// traceID and spanID identify the tracing span the error occurred in (see WithSpanIDs).
+	traceID string
+	spanID  string
+
+	// localized is the translation attached by a Localizer, if any.
+	localized *localizedMessage
+
+	// codeSet, typeSet and stackWanted report whether WithCode, WithType and WithStack
+	// were applied by the current call to applyOpts.
+	codeSet     bool
+	typeSet     bool
+	stackWanted bool
+
+	// tracePending reports whether the last call site was recorded by the current call
+	// and is yet to be checked against the trace mode (see settleTrace).
+	tracePending bool
 }
 
 func (e errorX) Error() string {
+	if e.template != "" {
+		return renderTemplate(e.template, e.params)
+	}
 	return e.msg
 }
 
@@ -109,7 +177,37 @@ func (e errorX) Type() Type {
 }
 
 func (e errorX) Trace() string {
-	return e.trace
+	r := currentTraceRenderer()
+	if r.Filter == nil {
+		if f := currentTraceConfig().Filter; !f.isZero() {
+			r.Filter = &f
+		}
+	}
+	return r.render(resolveFrames(e.callers), e.rawTrace)
+}
+
+// Frames returns the call sites recorded in the error's trace, the most recent first.
+// Trace is rendered from them.
+func (e errorX) Frames() []Frame {
+	return resolveFrames(e.callers)
+}
+
+// Stack returns the full call stack captured when the error was created.
+// It is empty unless the capture was enabled with WithStack or EnableStackCapture.
+func (e errorX) Stack() Stack {
+	return e.stack
+}
+
+// Created returns the time the error was created.
+// It is zero if unknown, for example for errors received from peers that do not send it.
+func (e errorX) Created() time.Time {
+	return e.created
+}
+
+// Hops returns the steps the error went through after its creation, the oldest first:
+// wraps, and the sending and receiving across services.
+func (e errorX) Hops() []Hop {
+	return slices.Clone(e.hops)
 }
 
 func (e errorX) Fields() M {
@@ -120,6 +218,18 @@ func (e errorX) Details() D {
 	return e.details
 }
 
+// Template returns the message template, if the message was built from one.
+// Placeholders have the form "{name}", e.g. "user {user_id} not found".
+func (e errorX) Template() string {
+	return e.template
+}
+
+// Params returns the named parameters of the message template.
+// Clients can use them together with the code to re-render the message, e.g. in another language.
+func (e errorX) Params() P {
+	return e.params
+}
+
 func (e errorX) Is(target error) bool {
 	if target == nil {
 		return false
@@ -127,6 +237,12 @@ func (e errorX) Is(target error) bool {
 	return e.origin == target
 }
 
+// Unwrap returns the wrapped error, so that errors.Is and errors.As
+// see the errors wrapped by Wrap.
+func (e errorX) Unwrap() error {
+	return e.origin
+}
+
 func (e *errorX) clone() *errorX {
 	fieldsClone := make(M)
 	maps.Copy(fieldsClone, e.fields)
@@ -134,14 +250,36 @@ func (e *errorX) clone() *errorX {
 	detailsClone := make(D)
 	maps.Copy(detailsClone, e.details)
 
+	var paramsClone P
+	if e.params != nil {
+		paramsClone = make(P)
+		maps.Copy(paramsClone, e.params)
+	}
+
 	return &errorX{
-		code:    e.code,
-		msg:     e.msg,
-		type_:   e.type_,
-		fields:  fieldsClone,
-		details: detailsClone,
-		trace:   e.trace,
-		origin:  e.origin,
+		code:      e.code,
+		msg:       e.msg,
+		publicMsg: e.publicMsg,
+		template:  e.template,
+		params:    paramsClone,
+		type_:     e.type_,
+		fields:    fieldsClone,
+		details:   detailsClone,
+		origin:    e.origin,
+
+		rawTrace: e.rawTrace,
+		stack:    e.stack,
+
+		// Reserve room for the call site and hop recorded by the caller of clone.
+		callers: append(make([]caller, 0, len(e.callers)+1), e.callers...),
+		hops:    append(make([]Hop, 0, len(e.hops)+1), e.hops...),
+		created: e.created,
+
+		retryable: e.retryable,
+		temporary: e.temporary,
+		traceID:   e.traceID,
+		spanID:    e.spanID,
+		localized: e.localized,
 	}
 }
 
@@ -153,24 +291,84 @@ func newDefault(msg string) *errorX {
 		fields:  make(M),
 		details: make(D),
 		origin:  errors.New(msg),
+		created: clockNow(),
 	}
 }
 
+// wrapFromError creates an errorX wrapping a non-errorX error.
+//
+// If an errorX is in the chain of err, for example because it was wrapped with
+// fmt.Errorf("...: %w", e), its code, type, fields and other data are inherited,
+// while the message is taken from err.
 func wrapFromError(err error) *errorX {
+	var inner *errorX
+	if errors.As(err, &inner) {
+		e := inner.clone()
+		e.msg = err.Error()
+		e.template = ""
+		e.origin = err
+		return e
+	}
+
 	return &errorX{
 		code:    DefaultCode,
 		msg:     err.Error(),
-		type_:   DefaultType,
+		type_:   typeFromError(err),
 		fields:  make(M),
 		details: make(D),
 		origin:  err,
+		created: clockNow(),
+	}
+}
+
+// errorXOf returns the error as an errorX for the boundary encoders.
+// Errors wrapping an errorX inherit its data (see wrapFromError),
+// other errors are converted to a default errorX.
+func errorXOf(err error) *errorX {
+	if e, ok := err.(*errorX); ok {
+		return e
 	}
+
+	var inner *errorX
+	if errors.As(err, &inner) {
+		return wrapFromError(err)
+	}
+	return newDefault(err.Error())
 }
 
 func applyOpts(e *errorX, opts []OptionFunc) {
+	e.codeSet, e.typeSet, e.stackWanted = false, false, false
+
 	for _, opt := range opts {
 		if opt != nil {
 			opt(e)
 		}
 	}
+
+	if e.codeSet {
+		applyCatalog(e)
+	}
+
+	e.settleTrace()
+}
+
+// typeFromError returns the error type for a non-ErrorX error.
+// Context errors and errors reporting a timeout, such as network errors (see net.Error), are mapped
+// to T_Timeout and T_Canceled, other errors to the default type.
+func typeFromError(err error) Type {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), isTimeout(err):
+		return T_Timeout
+	case errors.Is(err, context.Canceled):
+		return T_Canceled
+	}
+	return DefaultType
+}
+
+// isTimeout reports whether any error in the tree of err reports a timeout with a `Timeout() bool` method.
+func isTimeout(err error) bool {
+	return walkErrors(err, func(err error) bool {
+		t, ok := err.(interface{ Timeout() bool })
+		return ok && t.Timeout()
+	})
 }
@@ -1,6 +1,7 @@
 package errx_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -29,6 +30,25 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestNewDepth(t *testing.T) {
+	err := newDepthHelper()
+
+	frames := errx.GetFrames(err)
+	if len(frames) != 1 || frames[0].ShortFunction() != "errx_test.TestNewDepth" {
+		t.Errorf("expected the helper's caller in the trace, got %v", frames)
+	}
+	if stack := errx.GetStack(err).Frames(); len(stack) == 0 || stack[0].ShortFunction() != "errx_test.TestNewDepth" {
+		t.Errorf("expected the stack to start at the helper's caller, got %v", stack)
+	}
+	if errx.GetCode(err) != "HELPER" {
+		t.Errorf("unexpected code: %v", errx.GetCode(err))
+	}
+}
+
+func newDepthHelper() error {
+	return errx.NewDepth(1, "helper error", errx.WithCode("HELPER"), errx.WithStack())
+}
+
 func TestNewf(t *testing.T) {
 	t.Run("create new formatted error", func(t *testing.T) {
 		err := errx.Newf("error code: %d", 404)
@@ -59,6 +79,15 @@ func TestWrap(t *testing.T) {
 			t.Errorf("unexpected wrapped error message: %v", e.Error())
 		}
 	})
+
+	t.Run("wrap context errors", func(t *testing.T) {
+		if typ := errx.GetType(errx.Wrap(context.DeadlineExceeded)); typ != errx.T_Timeout {
+			t.Errorf("expected type T_Timeout, got %v", typ)
+		}
+		if typ := errx.GetType(errx.Wrap(fmt.Errorf("query: %w", context.Canceled))); typ != errx.T_Canceled {
+			t.Errorf("expected type T_Canceled, got %v", typ)
+		}
+	})
 }
 
 func TestIs(t *testing.T) {
@@ -124,3 +153,34 @@ func TestIs(t *testing.T) {
 		}
 	})
 }
+
+// stubErrorX implements ErrorX without the concrete type, as test doubles do.
+type stubErrorX struct{}
+
+func (stubErrorX) Error() string        { return "stub" }
+func (stubErrorX) Code() string         { return "STUB" }
+func (stubErrorX) Type() errx.Type      { return errx.T_Conflict }
+func (stubErrorX) Trace() string        { return "" }
+func (stubErrorX) Fields() errx.M       { return nil }
+func (stubErrorX) Details() errx.D      { return nil }
+func (stubErrorX) Is(target error) bool { return false }
+
+func TestCustomErrorX(t *testing.T) {
+	var err error = stubErrorX{}
+	if _, ok := err.(errx.ErrorX); !ok {
+		t.Fatalf("expected stub to implement ErrorX")
+	}
+
+	if errx.GetCode(err) != "STUB" || errx.GetType(err) != errx.T_Conflict {
+		t.Errorf("unexpected code or type: %v, %v", errx.GetCode(err), errx.GetType(err))
+	}
+	if len(errx.GetFrames(err)) != 0 || errx.GetStack(err).Len() != 0 || len(errx.GetHops(err)) != 0 {
+		t.Errorf("expected no frames, stack or hops")
+	}
+	if _, ok := errx.GetCreated(err); ok {
+		t.Errorf("expected no creation time")
+	}
+	if errx.GetTemplate(err) != "" || errx.GetParams(err) != nil {
+		t.Errorf("expected no template")
+	}
+}
@@ -0,0 +1,113 @@
+// Package errxotel records errx errors on OpenTelemetry spans,
+// and joins errors to the traces they occurred in.
+//
+// Record returned errors on the active span, typically once, where the span ends:
+//
+//	ctx, span := tracer.Start(ctx, "GetUser")
+//	defer span.End()
+//
+//	user, err := s.repo.Find(ctx, id)
+//	if err != nil {
+//	    errxotel.RecordError(ctx, err)
+//	    return nil, errx.Wrap(err, errxotel.WithSpanContext(ctx))
+//	}
+//
+// WithSpanContext stores the trace and span IDs in the error (see errx.WithSpanIDs),
+// so that logged errors can be looked up in the tracing backend.
+package errxotel
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/code19m/errx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of the exception events recorded by RecordError.
+const (
+	AttrCode  = attribute.Key("errx.code")
+	AttrType  = attribute.Key("errx.type")
+	AttrTrace = attribute.Key("errx.trace")
+
+	// AttrFieldPrefix prefixes the name of each validation field, e.g. "errx.field.email".
+	AttrFieldPrefix = "errx.field."
+
+	// AttrStacktrace holds the stack captured at creation, if any (see errx.WithStack).
+	// It is the key of the OpenTelemetry semantic conventions for exceptions.
+	AttrStacktrace = attribute.Key("exception.stacktrace")
+)
+
+// RecordError records the error on the span active in ctx as an exception event,
+// with its code, type, validation fields and trace as attributes, and sets the span status to Error.
+//
+// Errors that are not ErrorX are recorded with the default code and type.
+// If the error is nil or there is no recording span in ctx, no action is taken.
+func RecordError(ctx context.Context, err error, opts ...trace.EventOption) {
+	RecordSpanError(trace.SpanFromContext(ctx), err, opts...)
+}
+
+// RecordSpanError records the error on the given span, like RecordError.
+func RecordSpanError(span trace.Span, err error, opts ...trace.EventOption) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	e := errx.AsErrorX(err)
+	span.RecordError(err, append(opts[:len(opts):len(opts)], trace.WithAttributes(Attributes(e)...))...)
+	span.SetStatus(codes.Error, e.Error())
+}
+
+// Attributes returns the attributes describing the error, as recorded by RecordError.
+// They can also be set on spans or metrics directly.
+func Attributes(e errx.ErrorX) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		AttrCode.String(e.Code()),
+		AttrType.String(e.Type().String()),
+	}
+	if t := e.Trace(); t != "" {
+		attrs = append(attrs, AttrTrace.String(t))
+	}
+
+	fields := e.Fields()
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		attrs = append(attrs, attribute.String(AttrFieldPrefix+name, fields[name]))
+	}
+
+	if stack := errx.GetStack(e); stack.Len() > 0 {
+		attrs = append(attrs, AttrStacktrace.String(stack.String()))
+	}
+	return attrs
+}
+
+// WithSpanContext stores the trace and span IDs of the span active in ctx in the error
+// (see errx.WithSpanIDs), so that logs and traces can be joined.
+// If there is no valid span context in ctx, no IDs are stored.
+func WithSpanContext(ctx context.Context) errx.OptionFunc {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return errx.WithSpanIDs(sc.TraceID().String(), sc.SpanID().String())
+}
+
+// SpanContext returns the trace and span IDs stored by WithSpanContext
+// in the first ErrorX of the error's chain. If they were stored several times,
+// as the error went up nested spans, the most recent ones are returned.
+// The boolean result reports whether the error carries them.
+func SpanContext(err error) (traceID trace.TraceID, spanID trace.SpanID, ok bool) {
+	t, s, found := errx.GetSpanIDs(err)
+	if !found {
+		return traceID, spanID, false
+	}
+
+	traceID, terr := trace.TraceIDFromHex(t)
+	spanID, serr := trace.SpanIDFromHex(s)
+	if terr != nil || serr != nil {
+		return trace.TraceID{}, trace.SpanID{}, false
+	}
+	return traceID, spanID, true
+}
@@ -0,0 +1,174 @@
+package errxotel_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+	"github.com/code19m/errx/errxotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracer(t *testing.T) (trace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider.Tracer("errxotel_test"), exporter
+}
+
+func attrMap(attrs []attribute.KeyValue) map[attribute.Key]string {
+	m := make(map[attribute.Key]string, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value.Emit()
+	}
+	return m
+}
+
+func TestRecordError(t *testing.T) {
+	t.Run("record ErrorX as exception event", func(t *testing.T) {
+		tracer, exporter := newTracer(t)
+		ctx, span := tracer.Start(context.Background(), "op")
+
+		err := errx.New("invalid user",
+			errx.WithCode("INVALID_USER"),
+			errx.WithType(errx.T_Validation),
+			errx.WithFields(errx.M{"email": "invalid format", "age": "too young"}),
+		)
+		errxotel.RecordError(ctx, err)
+		span.End()
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+		s := spans[0]
+		if s.Status.Code != codes.Error || s.Status.Description != "invalid user" {
+			t.Errorf("unexpected status: %+v", s.Status)
+		}
+		if len(s.Events) != 1 || s.Events[0].Name != "exception" {
+			t.Fatalf("expected exception event, got %+v", s.Events)
+		}
+
+		attrs := attrMap(s.Events[0].Attributes)
+		want := map[attribute.Key]string{
+			"exception.message": "invalid user",
+			errxotel.AttrCode:   "INVALID_USER",
+			errxotel.AttrType:   "T_Validation",
+			"errx.field.email":  "invalid format",
+			"errx.field.age":    "too young",
+		}
+		for k, v := range want {
+			if attrs[k] != v {
+				t.Errorf("expected %s=%q, got %q", k, v, attrs[k])
+			}
+		}
+		if !strings.Contains(attrs[errxotel.AttrTrace], "errxotel_test.go:") {
+			t.Errorf("expected trace attribute, got %q", attrs[errxotel.AttrTrace])
+		}
+		if _, ok := attrs[errxotel.AttrStacktrace]; ok {
+			t.Errorf("expected no stacktrace without a captured stack")
+		}
+	})
+
+	t.Run("captured stack", func(t *testing.T) {
+		tracer, exporter := newTracer(t)
+		ctx, span := tracer.Start(context.Background(), "op")
+		errxotel.RecordError(ctx, errx.New("boom", errx.WithStack()))
+		span.End()
+
+		attrs := attrMap(exporter.GetSpans()[0].Events[0].Attributes)
+		if !strings.HasPrefix(attrs[errxotel.AttrStacktrace], "goroutine ") {
+			t.Errorf("expected stacktrace attribute, got %q", attrs[errxotel.AttrStacktrace])
+		}
+	})
+
+	t.Run("standard error", func(t *testing.T) {
+		tracer, exporter := newTracer(t)
+		ctx, span := tracer.Start(context.Background(), "op")
+		errxotel.RecordError(ctx, fmt.Errorf("plain"))
+		span.End()
+
+		attrs := attrMap(exporter.GetSpans()[0].Events[0].Attributes)
+		if attrs[errxotel.AttrCode] != errx.DefaultCode || attrs[errxotel.AttrType] != errx.DefaultType.String() {
+			t.Errorf("expected default code and type, got %v", attrs)
+		}
+	})
+
+	t.Run("nil error and no span", func(t *testing.T) {
+		tracer, exporter := newTracer(t)
+		ctx, span := tracer.Start(context.Background(), "op")
+		errxotel.RecordError(ctx, nil)
+		span.End()
+
+		if s := exporter.GetSpans()[0]; len(s.Events) != 0 || s.Status.Code != codes.Unset {
+			t.Errorf("expected span to be unchanged, got %+v", s)
+		}
+
+		errxotel.RecordError(context.Background(), errx.New("no span"))
+	})
+}
+
+func TestWithSpanContext(t *testing.T) {
+	tracer, _ := newTracer(t)
+
+	t.Run("store span IDs", func(t *testing.T) {
+		ctx, span := tracer.Start(context.Background(), "op")
+		defer span.End()
+
+		err := errx.New("error", errxotel.WithSpanContext(ctx))
+
+		traceHex, spanHex, ok := errx.GetSpanIDs(err)
+		if !ok || traceHex != span.SpanContext().TraceID().String() || spanHex != span.SpanContext().SpanID().String() {
+			t.Errorf("unexpected span IDs: %v %v %v", traceHex, spanHex, ok)
+		}
+
+		traceID, spanID, ok := errxotel.SpanContext(fmt.Errorf("wrapped: %w", err))
+		if !ok || traceID != span.SpanContext().TraceID() || spanID != span.SpanContext().SpanID() {
+			t.Errorf("unexpected span context: %v %v %v", traceID, spanID, ok)
+		}
+	})
+
+	t.Run("nested spans", func(t *testing.T) {
+		ctx, outer := tracer.Start(context.Background(), "outer")
+		defer outer.End()
+		inner, span := tracer.Start(ctx, "inner")
+		defer span.End()
+
+		err := errx.Wrap(errx.New("error", errxotel.WithSpanContext(inner)), errxotel.WithSpanContext(ctx))
+
+		_, spanID, ok := errxotel.SpanContext(err)
+		if !ok || spanID != outer.SpanContext().SpanID() {
+			t.Errorf("expected the most recent span, got %v %v", spanID, ok)
+		}
+	})
+
+	t.Run("span IDs survive prefixes and peers", func(t *testing.T) {
+		ctx, span := tracer.Start(context.Background(), "op")
+		defer span.End()
+
+		err := errx.New("error", errxotel.WithSpanContext(ctx), errx.WithTracePrefix("users"))
+		_, received := errx.FromGRPCError(errx.ToGRPCError(errx.Wrap(err, errx.WithTracePrefix("api"))))
+
+		traceID, spanID, ok := errxotel.SpanContext(received)
+		if !ok || traceID != span.SpanContext().TraceID() || spanID != span.SpanContext().SpanID() {
+			t.Errorf("unexpected span context: %v %v %v", traceID, spanID, ok)
+		}
+	})
+
+	t.Run("no span", func(t *testing.T) {
+		err := errx.New("error", errxotel.WithSpanContext(context.Background()))
+		if len(err.(errx.ErrorX).Details()) != 0 {
+			t.Errorf("expected no details, got %v", err.(errx.ErrorX).Details())
+		}
+		if _, _, ok := errxotel.SpanContext(err); ok {
+			t.Errorf("expected no span context")
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package errx
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// TraceFilter holds the rules that keep traces readable, set in TraceConfig.Filter and TraceRenderer.Filter.
+//
+// The rules of the tracing configuration are applied when call sites are recorded,
+// so dropped and collapsed frames cost nothing afterwards. The rules of a renderer are applied
+// when a trace is rendered, which also covers frames received from peers.
+type TraceFilter struct {
+	// DropPackages drops the frames of the packages matching any of the patterns.
+	// A pattern is an import path, which matches that package only, or an import path
+	// followed by "/...", which matches the package and its subpackages, like in the go command:
+	//
+	//	"google.golang.org/grpc/...", "github.com/acme/app/gen/..."
+	DropPackages []string
+
+	// DropFunctions drops the frames of the functions matching any of the patterns.
+	// Patterns are matched with path.Match against both the package-qualified function name
+	// and the short one (see Frame.ShortFunction):
+	//
+	//	"middleware.*", "*.(*Server).ServeHTTP", "github.com/acme/app/internal/retry.*"
+	DropFunctions []string
+
+	// CollapseRepeats collapses consecutive frames of the same call site,
+	// as recorded by recursive helpers, into one frame with a count (see Frame.Repeat).
+	CollapseRepeats bool
+
+	// MaxDepth caps the number of frames. Beyond it, the frames in the middle of the trace
+	// are replaced with a placeholder frame holding their number (see Frame.Omitted),
+	// while the MaxDepth/2 frames closest to the origin and the most recent ones are kept.
+	// Zero means no cap.
+	MaxDepth int
+}
+
+// isZero reports whether the filter has no rules.
+func (f TraceFilter) isZero() bool {
+	return len(f.DropPackages) == 0 && len(f.DropFunctions) == 0 && !f.CollapseRepeats && f.MaxDepth <= 0
+}
+
+// clone returns a copy of the filter that does not share its patterns.
+func (f TraceFilter) clone() TraceFilter {
+	f.DropPackages = slices.Clone(f.DropPackages)
+	f.DropFunctions = slices.Clone(f.DropFunctions)
+	return f
+}
+
+// Apply applies the rules to frames given the most recent first, as returned by GetFrames.
+func (f TraceFilter) Apply(frames []Frame) []Frame {
+	if f.isZero() {
+		return frames
+	}
+
+	out := make([]Frame, 0, len(frames))
+	for _, fr := range frames {
+		if fr.Omitted == 0 && f.drops(fr) {
+			continue
+		}
+		if f.CollapseRepeats && len(out) > 0 && sameCallSite(out[len(out)-1], fr) {
+			out[len(out)-1].Repeat = max(out[len(out)-1].Repeat, 1) + max(fr.Repeat, 1)
+			continue
+		}
+		out = append(out, fr)
+	}
+
+	if f.MaxDepth > 0 {
+		oldest := f.MaxDepth / 2
+		out = capDepth(out, f.MaxDepth-oldest, oldest,
+			func(fr Frame) int { return fr.Omitted },
+			func(n int, first Frame) Frame { return Frame{Omitted: n, Service: first.Service} },
+		)
+	}
+	return out
+}
+
+// capture applies the rules to the call site recorded last, given the call sites the oldest first.
+func (f TraceFilter) capture(callers []caller) []caller {
+	if f.isZero() || len(callers) == 0 {
+		return callers
+	}
+
+	last := callers[len(callers)-1]
+	if (len(f.DropPackages) > 0 || len(f.DropFunctions) > 0) && f.drops(last.resolve()) {
+		return callers[:len(callers)-1]
+	}
+
+	if f.CollapseRepeats && len(callers) > 1 {
+		prev := &callers[len(callers)-2]
+		if last.pc != 0 && prev.pc == last.pc && prev.service == last.service {
+			prev.repeat = max(prev.repeat, 1) + 1
+			return callers[:len(callers)-1]
+		}
+	}
+
+	if f.MaxDepth > 0 {
+		oldest := f.MaxDepth / 2
+		callers = capDepth(callers, oldest, f.MaxDepth-oldest,
+			func(c caller) int { return c.omitted },
+			func(n int, first caller) caller { return caller{omitted: n, service: first.service} },
+		)
+	}
+	return callers
+}
+
+// drops reports whether the frame is dropped by the package and function patterns.
+func (f TraceFilter) drops(fr Frame) bool {
+	for _, pattern := range f.DropPackages {
+		if matchPackage(fr.Package, pattern) {
+			return true
+		}
+	}
+	for _, pattern := range f.DropFunctions {
+		if ok, _ := path.Match(pattern, fr.Function); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, fr.ShortFunction()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPackage reports whether the package import path matches the pattern (see TraceFilter.DropPackages).
+func matchPackage(pkg, pattern string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	return pkg == pattern
+}
+
+// sameCallSite reports whether the frames are of the same call site.
+func sameCallSite(a, b Frame) bool {
+	return a.Omitted == 0 && b.Omitted == 0 &&
+		a.File == b.File && a.Path == b.Path && a.Line == b.Line && a.Function == b.Function && a.Service == b.Service
+}
+
+// capDepth keeps the first keepStart and the last keepEnd items that are not placeholders,
+// and replaces the items between them with a single placeholder counting them.
+// Placeholders in the replaced items are merged into it.
+func capDepth[T any](items []T, keepStart, keepEnd int, omittedOf func(T) int, placeholder func(n int, first T) T) []T {
+	n := 0
+	for _, item := range items {
+		if omittedOf(item) == 0 {
+			n++
+		}
+	}
+	if n <= keepStart+keepEnd {
+		return items
+	}
+
+	start := 0
+	for kept := 0; kept < keepStart; start++ {
+		if omittedOf(items[start]) == 0 {
+			kept++
+		}
+	}
+	end := len(items)
+	for kept := 0; kept < keepEnd; {
+		end--
+		if omittedOf(items[end]) == 0 {
+			kept++
+		}
+	}
+
+	omitted := 0
+	for _, item := range items[start:end] {
+		omitted += max(omittedOf(item), 1)
+	}
+
+	out := make([]T, 0, start+1+len(items)-end)
+	out = append(out, items[:start]...)
+	out = append(out, placeholder(omitted, items[start]))
+	return append(out, items[end:]...)
+}
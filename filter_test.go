@@ -0,0 +1,152 @@
+package errx_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestTraceFilterApply(t *testing.T) {
+	frame := func(pkg, fn string, line int) errx.Frame {
+		return errx.Frame{File: "f.go", Line: line, Function: pkg + "." + fn, Package: pkg}
+	}
+
+	t.Run("drop packages and functions", func(t *testing.T) {
+		frames := []errx.Frame{
+			frame("github.com/acme/app/users", "Get", 1),
+			frame("google.golang.org/grpc", "invoke", 2),
+			frame("google.golang.org/grpc/internal/transport", "write", 3),
+			frame("google.golang.org/grpcx", "call", 4),
+			frame("github.com/acme/app/middleware", "Auth", 5),
+			frame("github.com/acme/app/api", "(*Server).ServeHTTP", 6),
+			frame("github.com/acme/app/gen", "Handle", 7),
+		}
+		f := errx.TraceFilter{
+			DropPackages:  []string{"google.golang.org/grpc/...", "github.com/acme/app/gen"},
+			DropFunctions: []string{"middleware.*", "*.(*Server).ServeHTTP"},
+		}
+
+		got := f.Apply(frames)
+		if len(got) != 2 || got[0].Line != 1 || got[1].Line != 4 {
+			t.Errorf("unexpected frames: %v", got)
+		}
+	})
+
+	t.Run("collapse repeats", func(t *testing.T) {
+		walk := frame("tree", "walk", 10)
+		frames := []errx.Frame{frame("tree", "visit", 1), walk, walk, walk, frame("tree", "Walk", 20), walk}
+
+		got := errx.TraceFilter{CollapseRepeats: true}.Apply(frames)
+		want := []errx.Frame{frame("tree", "visit", 1), walk, frame("tree", "Walk", 20), walk}
+		want[1].Repeat = 3
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+		if s := (errx.TraceRenderer{}).RenderFrame(got[1]); s != "[f.go:10] tree.walk (x3)" {
+			t.Errorf("unexpected rendering: %v", s)
+		}
+	})
+
+	t.Run("cap depth", func(t *testing.T) {
+		var frames []errx.Frame
+		for i := 1; i <= 10; i++ {
+			frames = append(frames, frame("p", "f", i))
+		}
+
+		got := errx.TraceFilter{MaxDepth: 5}.Apply(frames)
+		lines := []int{}
+		for _, f := range got {
+			lines = append(lines, f.Line)
+		}
+		if !reflect.DeepEqual(lines, []int{1, 2, 3, 0, 9, 10}) || got[3].Omitted != 5 {
+			t.Errorf("expected the 3 most recent and 2 oldest frames, got %v", got)
+		}
+
+		again := errx.TraceFilter{MaxDepth: 3}.Apply(got)
+		if len(again) != 4 || again[2].Omitted != 7 {
+			t.Errorf("expected placeholders to be merged, got %v", again)
+		}
+		if s := (errx.TraceRenderer{}).RenderFrame(again[2]); s != "... 7 frames omitted" {
+			t.Errorf("unexpected rendering: %v", s)
+		}
+	})
+
+	t.Run("render with filter", func(t *testing.T) {
+		frames := []errx.Frame{frame("a", "f", 1), frame("b", "g", 2), frame("b", "g", 2)}
+		r := errx.TraceRenderer{Filter: &errx.TraceFilter{DropPackages: []string{"a"}, CollapseRepeats: true}}
+		if s := r.RenderFrames(frames); s != "[f.go:2] b.g (x2)" {
+			t.Errorf("unexpected trace: %v", s)
+		}
+	})
+}
+
+func TestTraceFilterCapture(t *testing.T) {
+	defer errx.SetTraceConfig(errx.TraceConfig{})
+
+	t.Run("collapse repeated wraps", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{Filter: errx.TraceFilter{CollapseRepeats: true}})
+
+		err := errx.New("error")
+		for range 5 {
+			err = errx.Wrap(err)
+		}
+
+		frames := errx.GetFrames(err)
+		if len(frames) != 2 || frames[0].Repeat != 5 || frames[1].Repeat != 0 {
+			t.Errorf("expected collapsed wraps, got %v", frames)
+		}
+
+		_, received := errx.FromGRPCError(errx.ToGRPCError(err))
+		if frames := errx.GetFrames(received); len(frames) != 4 || frames[2].Repeat != 5 {
+			t.Errorf("expected counts to be carried over gRPC, got %v", frames)
+		}
+	})
+
+	t.Run("drop functions", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{Filter: errx.TraceFilter{DropFunctions: []string{"errx_test.filterHelper"}}})
+
+		frames := errx.GetFrames(errx.Wrap(filterHelper()))
+		if len(frames) != 1 || frames[0].ShortFunction() != "errx_test.TestTraceFilterCapture.func2" {
+			t.Errorf("expected helper frame to be dropped, got %v", frames)
+		}
+	})
+
+	t.Run("cap depth", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{Filter: errx.TraceFilter{MaxDepth: 4}})
+
+		err := filterHelper()
+		for range 9 {
+			err = errx.Wrap(err)
+		}
+
+		frames := errx.GetFrames(err)
+		if len(frames) != 5 || frames[2].Omitted != 6 || frames[4].ShortFunction() != "errx_test.filterHelper" {
+			t.Errorf("expected capped trace, got %v", frames)
+		}
+
+		trace := err.(errx.ErrorX).Trace()
+		if !strings.Contains(trace, " ➡️ ... 6 frames omitted ➡️ ") {
+			t.Errorf("unexpected trace: %v", trace)
+		}
+		hops, perr := errx.ParseTrace(trace)
+		if perr != nil || len(hops[0].Frames) != 5 || hops[0].Frames[2].Omitted != 6 {
+			t.Errorf("expected trace to be parsed, got %v %v", hops, perr)
+		}
+	})
+
+	t.Run("config is copied", func(t *testing.T) {
+		patterns := []string{"a"}
+		errx.SetTraceConfig(errx.TraceConfig{Filter: errx.TraceFilter{DropPackages: patterns}})
+		patterns[0] = "b"
+
+		if got := errx.GetTraceConfig().Filter.DropPackages; got[0] != "a" {
+			t.Errorf("expected patterns to be copied, got %v", got)
+		}
+	})
+}
+
+func filterHelper() error {
+	return errx.New("error")
+}
@@ -0,0 +1,179 @@
+package errx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GraphQLError is a single entry of the "errors" list of a GraphQL response,
+// as defined by the GraphQL specification.
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is a location in a GraphQL document.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ToGraphQLErrors converts an error into GraphQL error entries.
+//
+// Joined errors (errors implementing `Unwrap() []error`, such as those created by errors.Join)
+// are flattened into one entry per joined error.
+// Each entry holds the error code, type name, validation fields, and the message template
+// with its parameters in its extensions.
+// The optional path is set on every entry.
+//
+// If the error is nil, nil is returned.
+func ToGraphQLErrors(err error, path ...any) []GraphQLError {
+	if err == nil {
+		return nil
+	}
+
+	var result []GraphQLError
+	for _, leaf := range flattenJoined(err) {
+		result = append(result, toGraphQL(errorXOf(leaf), path))
+	}
+	return result
+}
+
+// FromGraphQLError converts a GraphQL error entry into an ErrorX.
+//
+// It is intended for use on the client side, to convert errors returned by downstream GraphQL services.
+// The function returns a boolean indicating whether the entry carried ErrorX extensions (`true`) or not (`false`).
+//
+// The path of the entry, if any, is recorded in the details under the "graphql.path" key.
+// Optional modifications can be applied via OptionFunc.
+func FromGraphQLError(ge GraphQLError, opts ...OptionFunc) (bool, error) {
+	e, ok := fromGraphQL(ge)
+	e.addTrace(2)
+	applyOpts(e, opts)
+	return ok, e
+}
+
+// FromGraphQLResponse converts the "errors" list of a GraphQL response body into an error.
+//
+// If the response has no errors, nil is returned.
+// A single error is returned as is, several errors are combined with errors.Join.
+// If the body cannot be decoded, a default ErrorX instance is returned.
+// Optional modifications are applied to every converted error.
+func FromGraphQLResponse(body []byte, opts ...OptionFunc) error {
+	var resp struct {
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		e := newDefault(fmt.Sprintf("invalid GraphQL response: %s", err.Error()))
+		e.addTrace(2)
+		applyOpts(e, opts)
+		return e
+	}
+
+	errs := make([]error, 0, len(resp.Errors))
+	for _, ge := range resp.Errors {
+		e, _ := fromGraphQL(ge)
+		e.addTrace(2)
+		applyOpts(e, opts)
+		errs = append(errs, e)
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// toGraphQL converts an ErrorX to a GraphQL error entry.
+func toGraphQL(e *errorX, path []any) GraphQLError {
+	ext := map[string]any{
+		"code": e.Code(),
+		"type": e.Type().String(),
+	}
+	if len(e.Fields()) > 0 {
+		ext["fields"] = e.Fields()
+	}
+	template, params := e.publicTemplate()
+	if template != "" {
+		ext["template"] = template
+	}
+	if len(params) > 0 {
+		ext["params"] = params
+	}
+
+	return GraphQLError{
+		Message:    e.PublicMessage(),
+		Path:       path,
+		Extensions: ext,
+	}
+}
+
+// fromGraphQL converts a GraphQL error entry to an ErrorX.
+// The boolean result reports whether the entry carried an ErrorX code.
+func fromGraphQL(ge GraphQLError) (*errorX, bool) {
+	e := newDefault(ge.Message)
+
+	if len(ge.Path) > 0 {
+		e.details["graphql.path"] = graphQLPathString(ge.Path)
+	}
+
+	code, ok := ge.Extensions["code"].(string)
+	if !ok || code == "" {
+		return e, false
+	}
+	e.code = CanonicalCode(code)
+
+	if name, ok := ge.Extensions["type"].(string); ok {
+		e.type_, _ = ParseType(name)
+	}
+
+	if fields, ok := ge.Extensions["fields"].(map[string]any); ok {
+		for k, v := range fields {
+			if s, ok := v.(string); ok {
+				e.fields[k] = s
+			}
+		}
+	}
+
+	if template, ok := ge.Extensions["template"].(string); ok {
+		e.template = template
+	}
+	if params, ok := ge.Extensions["params"].(map[string]any); ok {
+		e.params = P(params)
+	}
+
+	return e, true
+}
+
+// graphQLPathString renders a GraphQL path like "user.friends.0.name".
+func graphQLPathString(path []any) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// flattenJoined returns the leaves of a tree of joined errors.
+// Errors that do not implement `Unwrap() []error` are returned as a single leaf.
+func flattenJoined(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var leaves []error
+	for _, e := range joined.Unwrap() {
+		if e != nil {
+			leaves = append(leaves, flattenJoined(e)...)
+		}
+	}
+	return leaves
+}
@@ -0,0 +1,127 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestToGraphQLErrors(t *testing.T) {
+	t.Run("convert ErrorX to GraphQL error", func(t *testing.T) {
+		err := errx.New("invalid input",
+			errx.WithCode("INVALID_INPUT"),
+			errx.WithType(errx.T_Validation),
+			errx.WithFields(errx.M{"email": "invalid format"}),
+		)
+
+		gqlErrs := errx.ToGraphQLErrors(err, "createUser")
+		if len(gqlErrs) != 1 {
+			t.Fatalf("expected 1 error, got %d", len(gqlErrs))
+		}
+
+		ge := gqlErrs[0]
+		if ge.Message != "Invalid request" {
+			t.Errorf("unexpected message: %v", ge.Message)
+		}
+		if len(ge.Path) != 1 || ge.Path[0] != "createUser" {
+			t.Errorf("unexpected path: %v", ge.Path)
+		}
+		if ge.Extensions["code"] != "INVALID_INPUT" || ge.Extensions["type"] != "T_Validation" {
+			t.Errorf("unexpected extensions: %v", ge.Extensions)
+		}
+		if fields, _ := ge.Extensions["fields"].(errx.M); fields["email"] != "invalid format" {
+			t.Errorf("unexpected fields: %v", ge.Extensions["fields"])
+		}
+	})
+
+	t.Run("flatten joined errors", func(t *testing.T) {
+		err := errors.Join(
+			errx.New("first", errx.WithCode("FIRST")),
+			errors.Join(errx.New("second", errx.WithCode("SECOND")), errors.New("third")),
+		)
+
+		gqlErrs := errx.ToGraphQLErrors(err)
+		if len(gqlErrs) != 3 {
+			t.Fatalf("expected 3 errors, got %d", len(gqlErrs))
+		}
+		codes := []any{gqlErrs[0].Extensions["code"], gqlErrs[1].Extensions["code"], gqlErrs[2].Extensions["code"]}
+		if codes[0] != "FIRST" || codes[1] != "SECOND" || codes[2] != errx.DefaultCode {
+			t.Errorf("unexpected codes: %v", codes)
+		}
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		if gqlErrs := errx.ToGraphQLErrors(nil); gqlErrs != nil {
+			t.Errorf("expected nil, got %v", gqlErrs)
+		}
+	})
+}
+
+func TestFromGraphQLResponse(t *testing.T) {
+	t.Run("round trip through JSON", func(t *testing.T) {
+		src := errx.New("user not found", errx.WithCode("USER_NOT_FOUND"), errx.WithType(errx.T_NotFound))
+		body, _ := json.Marshal(map[string]any{
+			"data":   nil,
+			"errors": errx.ToGraphQLErrors(src, "user", 0),
+		})
+
+		err := errx.FromGraphQLResponse(body)
+		e, ok := err.(errx.ErrorX)
+		if !ok {
+			t.Fatalf("expected ErrorX, got %T", err)
+		}
+		if e.Code() != "USER_NOT_FOUND" || e.Type() != errx.T_NotFound {
+			t.Errorf("unexpected code or type: %v, %v", e.Code(), e.Type())
+		}
+		if e.Details()["graphql.path"] != "user.0" {
+			t.Errorf("unexpected path detail: %v", e.Details()["graphql.path"])
+		}
+	})
+
+	t.Run("multiple errors are joined", func(t *testing.T) {
+		body := []byte(`{"errors":[
+			{"message":"a","extensions":{"code":"A","type":"T_Validation","fields":{"name":"required"}}},
+			{"message":"b"}
+		]}`)
+
+		err := errx.FromGraphQLResponse(body)
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Fatalf("expected joined error, got %T", err)
+		}
+		errs := joined.Unwrap()
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %d", len(errs))
+		}
+		if errx.GetType(errs[0]) != errx.T_Validation || errs[0].(errx.ErrorX).Fields()["name"] != "required" {
+			t.Errorf("unexpected first error: %v", errs[0])
+		}
+		if errx.GetCode(errs[1]) != errx.DefaultCode {
+			t.Errorf("expected default code for second error, got %v", errx.GetCode(errs[1]))
+		}
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		if err := errx.FromGraphQLResponse([]byte(`{"data":{"ok":true}}`)); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		if err := errx.FromGraphQLResponse([]byte(`{`)); errx.GetType(err) != errx.T_Internal {
+			t.Errorf("expected internal error, got %v", err)
+		}
+	})
+}
+
+func TestFromGraphQLError(t *testing.T) {
+	ok, err := errx.FromGraphQLError(errx.GraphQLError{Message: "plain"})
+	if ok {
+		t.Errorf("expected unsuccessful conversion")
+	}
+	if err.Error() != "plain" {
+		t.Errorf("unexpected message: %v", err.Error())
+	}
+}
@@ -0,0 +1,206 @@
+package errx
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// HTTPErrorWriter writes an error to an HTTP response.
+type HTTPErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+
+// HTTPHandlerFunc is an HTTP handler that returns an error instead of writing it itself.
+// Returned errors are written with WriteHTTPError.
+type HTTPHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements the http.Handler interface.
+func (f HTTPHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteHTTPError(w, r, err)
+	}
+}
+
+const (
+	// RequestIDHeader is the header from which the request ID is read
+	// when recording panics in RecoverHTTP.
+	RequestIDHeader = "X-Request-Id"
+
+	// CodePanic is the error code of errors created from recovered panics.
+	CodePanic = "PANIC"
+
+	// StatusClientClosedRequest is the non-standard HTTP status used for T_Canceled errors,
+	// following the nginx convention.
+	StatusClientClosedRequest = 499
+)
+
+// WriteHTTPError writes the error to the HTTP response as a JSON body.
+//
+// The HTTP status is derived from the error type (see HTTPStatus) and the body
+// is the JSON representation of the error (see errorX.MarshalJSON) without its trace, frames and hops,
+// as responses may reach browsers and other untrusted clients. It can be read back with FromJSON.
+// If the error does not implement the ErrorX interface, it is wrapped into a default ErrorX instance.
+// If the error is nil, no action is taken.
+func WriteHTTPError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+
+	e := errorXOf(err)
+
+	body, merr := json.Marshal(publicJSON(e))
+	if merr != nil {
+		http.Error(w, e.PublicMessage(), HTTPStatus(e.Type()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(HTTPStatus(e.Type()))
+	_, _ = w.Write(body)
+}
+
+// RecoverHTTP returns a middleware that recovers panics raised by the next handler
+// and writes them as T_Internal errors using the given writer.
+//
+// The error carries the panic value, the goroutine stack, the request method and path,
+// and the request ID (see RequestIDHeader) in its details. Its message does not include the panic value,
+// which may hold internal data, and its trace points at the code that panicked.
+// If write is nil, WriteHTTPError is used, so panics are reported in the same way as returned errors.
+//
+// Panics with http.ErrAbortHandler are re-panicked, so the server can abort the response as usual.
+// So are panics raised after the handler started writing the response, as an error can no longer be written:
+// the server logs them and aborts the partial response.
+func RecoverHTTP(next http.Handler, write HTTPErrorWriter) http.Handler {
+	if write == nil {
+		write = WriteHTTPError
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler || tw.written {
+				panic(rec)
+			}
+			write(w, r, newFromPanic(rec, r))
+		}()
+
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// trackingWriter records whether the handler started writing the response.
+type trackingWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, which handlers streaming responses commonly assert.
+func (w *trackingWriter) Flush() {
+	w.written = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped writer, so that http.ResponseController reaches its other features.
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// newFromPanic creates a new ErrorX from a recovered panic value.
+func newFromPanic(rec any, r *http.Request) *errorX {
+	e := newDefault("panic recovered")
+	e.code = CodePanic
+	e.type_ = T_Internal
+	e.details = D{
+		"panic":       rec,
+		"stack":       string(debug.Stack()),
+		"http.method": r.Method,
+		"http.path":   r.URL.Path,
+	}
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		e.details["request_id"] = id
+	}
+	if err, ok := rec.(error); ok {
+		e.origin = err
+	}
+
+	if pc := panicPC(); pc != 0 && !currentTraceConfig().tracingOff() {
+		e.callers = append(e.callers, caller{pc: pc})
+		e.tracePending = true
+	} else {
+		e.addTrace(2)
+	}
+	e.settleTrace()
+	return e
+}
+
+// panicPC returns the program counter of the call site that raised the panic being recovered,
+// or zero if it cannot be found. It must be called from the deferred function recovering the panic.
+//
+// The call site is the first frame below runtime.gopanic outside of the runtime,
+// which skips the runtime helpers raising panics for nil dereferences or out of range indexes.
+func panicPC() uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+
+	panicking := false
+	for _, pc := range pcs[:n] {
+		fn := resolvePC(pc).Function
+		switch {
+		case fn == "runtime.gopanic":
+			panicking = true
+		case panicking && !strings.HasPrefix(fn, "runtime."):
+			return pc
+		}
+	}
+	return 0
+}
+
+// HTTPStatus returns the HTTP status code corresponding to the error type.
+// Unknown types are mapped to http.StatusInternalServerError.
+func HTTPStatus(t Type) int {
+	switch t {
+	case T_Internal:
+		return http.StatusInternalServerError
+	case T_Validation:
+		return http.StatusBadRequest
+	case T_NotFound:
+		return http.StatusNotFound
+	case T_Conflict:
+		return http.StatusConflict
+	case T_Authentication:
+		return http.StatusUnauthorized
+	case T_Forbidden:
+		return http.StatusForbidden
+	case T_Throttling:
+		return http.StatusTooManyRequests
+	case T_Unavailable:
+		return http.StatusServiceUnavailable
+	case T_Timeout:
+		return http.StatusGatewayTimeout
+	case T_Canceled:
+		return StatusClientClosedRequest
+	case T_PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case T_Unimplemented:
+		return http.StatusNotImplemented
+	case T_TooLarge:
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,226 @@
+package errx_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestWriteHTTPError(t *testing.T) {
+	t.Run("write ErrorX with status and JSON body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		errx.WriteHTTPError(rec, req, errx.New("user not found", errx.WithCode("USER_NOT_FOUND"), errx.WithType(errx.T_NotFound)))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("unexpected content type: %v", ct)
+		}
+
+		ok, err := errx.FromJSON(rec.Body.Bytes())
+		if !ok {
+			t.Fatalf("expected body to be decodable, got: %s", rec.Body.String())
+		}
+		if errx.GetCode(err) != "USER_NOT_FOUND" {
+			t.Errorf("unexpected code: %v", errx.GetCode(err))
+		}
+	})
+
+	t.Run("write regular error as internal", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		errx.WriteHTTPError(rec, req, errors.New("regular error"))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+
+	t.Run("nil error writes nothing", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		errx.WriteHTTPError(rec, req, nil)
+
+		if rec.Body.Len() != 0 {
+			t.Errorf("expected empty body, got %s", rec.Body.String())
+		}
+	})
+}
+
+func TestHTTPHandlerFunc(t *testing.T) {
+	t.Run("returned error is written", func(t *testing.T) {
+		h := errx.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return errx.New("bad input", errx.WithType(errx.T_Validation))
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestRecoverHTTP(t *testing.T) {
+	t.Run("recover panic into internal error", func(t *testing.T) {
+		var written error
+		write := func(w http.ResponseWriter, r *http.Request, err error) {
+			written = err
+			errx.WriteHTTPError(w, r, err)
+		}
+		h := errx.RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}), write)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/orders/7", nil)
+		req.Header.Set(errx.RequestIDHeader, "req-1")
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+		}
+
+		e := written.(errx.ErrorX)
+		if e.Type() != errx.T_Internal || e.Code() != errx.CodePanic {
+			t.Errorf("unexpected type or code: %v, %v", e.Type(), e.Code())
+		}
+		details := e.Details()
+		if details["panic"] != "boom" {
+			t.Errorf("expected panic value in details, got %v", details["panic"])
+		}
+		if details["http.method"] != http.MethodDelete || details["http.path"] != "/orders/7" {
+			t.Errorf("expected request method and path in details, got %v", details)
+		}
+		if details["request_id"] != "req-1" {
+			t.Errorf("expected request id in details, got %v", details["request_id"])
+		}
+		if stack, _ := details["stack"].(string); !contains(stack, "goroutine") {
+			t.Errorf("expected goroutine stack in details, got %v", details["stack"])
+		}
+	})
+
+	t.Run("nil writer uses WriteHTTPError", func(t *testing.T) {
+		h := errx.RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(errors.New("boom"))
+		}), nil)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		ok, err := errx.FromJSON(rec.Body.Bytes())
+		if !ok || errx.GetCode(err) != errx.CodePanic {
+			t.Errorf("expected panic error in body, got: %s", rec.Body.String())
+		}
+	})
+
+	t.Run("re-panic after the response was started", func(t *testing.T) {
+		var written error
+		h := errx.RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic("boom")
+		}), func(w http.ResponseWriter, r *http.Request, err error) { written = err })
+
+		rec := httptest.NewRecorder()
+		func() {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Errorf("expected the panic to be re-raised, got %v", p)
+				}
+			}()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+
+		if written != nil {
+			t.Errorf("expected no error to be written, got %v", written)
+		}
+		if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+			t.Errorf("expected the partial response to be left unchanged, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("re-panic on http.ErrAbortHandler", func(t *testing.T) {
+		h := errx.RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}), nil)
+
+		defer func() {
+			if rec := recover(); rec != http.ErrAbortHandler {
+				t.Errorf("expected http.ErrAbortHandler panic, got %v", rec)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		errType  errx.Type
+		expected int
+	}{
+		{errx.T_Internal, http.StatusInternalServerError},
+		{errx.T_Validation, http.StatusBadRequest},
+		{errx.T_NotFound, http.StatusNotFound},
+		{errx.T_Conflict, http.StatusConflict},
+		{errx.T_Authentication, http.StatusUnauthorized},
+		{errx.T_Forbidden, http.StatusForbidden},
+		{errx.T_Throttling, http.StatusTooManyRequests},
+		{errx.T_Unavailable, http.StatusServiceUnavailable},
+		{errx.T_Timeout, http.StatusGatewayTimeout},
+		{errx.T_Canceled, errx.StatusClientClosedRequest},
+		{errx.T_PreconditionFailed, http.StatusPreconditionFailed},
+		{errx.T_Unimplemented, http.StatusNotImplemented},
+		{errx.T_TooLarge, http.StatusRequestEntityTooLarge},
+		{errx.Type(99), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		if got := errx.HTTPStatus(tc.errType); got != tc.expected {
+			t.Errorf("for error type %v, expected status %d, got %d", tc.errType, tc.expected, got)
+		}
+	}
+}
+
+func TestRecoverHTTPHidesInternals(t *testing.T) {
+	h := errx.RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panicHelper()
+	}), nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	body := rec.Body.String()
+	for _, leak := range []string{"hunter2", `"trace"`, `"frames"`, `"hops"`, "http_test.go"} {
+		if strings.Contains(body, leak) {
+			t.Errorf("unexpected %q in body: %s", leak, body)
+		}
+	}
+}
+
+func TestRecoverHTTPTrace(t *testing.T) {
+	var written error
+	h := errx.RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panicHelper()
+	}), func(w http.ResponseWriter, r *http.Request, err error) { written = err })
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if msg := written.Error(); strings.Contains(msg, "hunter2") {
+		t.Errorf("expected panic value to be left out of the message, got %q", msg)
+	}
+	frames := errx.GetFrames(written)
+	if len(frames) != 1 || frames[0].ShortFunction() != "errx_test.panicHelper" {
+		t.Errorf("expected the panicking function in the trace, got %v", frames)
+	}
+}
+
+func panicHelper() {
+	panic("db password=hunter2")
+}
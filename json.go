@@ -0,0 +1,162 @@
+package errx
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// jsonErrorX is the JSON wire representation of an ErrorX.
+// It carries the same information as the gRPC proto message.
+type jsonErrorX struct {
+	Code     string  `json:"code"`
+	Message  string  `json:"message"`
+	Type     string  `json:"type"`
+	Fields   M       `json:"fields,omitempty"`
+	Trace    string  `json:"trace,omitempty"`
+	Frames   []Frame `json:"frames,omitempty"`
+	Template string  `json:"template,omitempty"`
+	Params   P       `json:"params,omitempty"`
+
+	Created *time.Time `json:"created,omitempty"`
+	Hops    []Hop      `json:"hops,omitempty"`
+
+	Retryable *bool `json:"retryable,omitempty"`
+	Temporary *bool `json:"temporary,omitempty"`
+
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+
+	LocalizedMessage *jsonLocalizedMessage `json:"localized_message,omitempty"`
+	LocalizedFields  M                     `json:"localized_fields,omitempty"`
+}
+
+// jsonLocalizedMessage is the JSON wire representation of a translation attached by a Localizer.
+// It mirrors google.rpc.LocalizedMessage.
+type jsonLocalizedMessage struct {
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The error is encoded with its code, public message (see WithPublicMessage), type name, validation fields, trace,
+// the message template with its parameters, the creation time and hops, the retryable and temporary overrides,
+// the span IDs, and the translation attached by a Localizer.
+// Details are not included, as they are intended for logging only.
+func (e errorX) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSON(&e))
+}
+
+// FromJSON converts a JSON encoded error (as produced by MarshalJSON) into an ErrorX.
+//
+// It is intended for use on the client side, for example when reading error responses
+// written by WriteHTTPError.
+// The function returns a boolean indicating whether the data was successfully decoded (`true`) or not (`false`).
+//
+// If the data cannot be decoded, a default ErrorX instance holding the raw data as its message is returned.
+// Optional modifications can be applied via OptionFunc.
+func FromJSON(data []byte, opts ...OptionFunc) (bool, error) {
+	var je jsonErrorX
+	if err := json.Unmarshal(data, &je); err != nil || je.Code == "" {
+		e := newDefault(string(data))
+		e.addTrace(2)
+		applyOpts(e, opts)
+		return false, e
+	}
+
+	e := fromJSON(&je)
+	e.addTrace(2)
+	applyOpts(e, opts)
+	return true, e
+}
+
+// toJSON converts an ErrorX to its JSON wire representation.
+// The public message is sent instead of the internal one.
+func toJSON(e *errorX) *jsonErrorX {
+	template, params := e.publicTemplate()
+	frames := e.Frames()
+	je := &jsonErrorX{
+		Code:     e.Code(),
+		Message:  e.PublicMessage(),
+		Type:     e.Type().String(),
+		Fields:   e.Fields(),
+		Trace:    TraceRenderer{}.render(frames, e.rawTrace),
+		Frames:   frames,
+		Template: template,
+		Params:   params,
+		Hops:     e.hops,
+
+		Retryable: e.retryable,
+		Temporary: e.temporary,
+		TraceID:   e.traceID,
+		SpanID:    e.spanID,
+	}
+	if !e.created.IsZero() {
+		je.Created = &e.created
+	}
+	if loc := e.localized; loc != nil {
+		je.LocalizedMessage = &jsonLocalizedMessage{Locale: loc.locale, Message: loc.message}
+		je.LocalizedFields = loc.fields
+	}
+	return je
+}
+
+// publicJSON converts an ErrorX to the JSON representation written to clients by WriteHTTPError.
+// Unlike toJSON, it leaves out the trace, frames and hops, which reveal the source layout of the service,
+// and the span IDs, which are intended for logging.
+func publicJSON(e *errorX) *jsonErrorX {
+	je := toJSON(e)
+	je.Trace, je.Frames, je.Hops = "", nil, nil
+	je.TraceID, je.SpanID = "", ""
+	return je
+}
+
+// fromJSON converts a JSON wire representation to an ErrorX.
+func fromJSON(je *jsonErrorX) *errorX {
+	t, _ := ParseType(je.Type)
+
+	fields := je.Fields
+	if fields == nil {
+		fields = make(M)
+	}
+
+	var loc *localizedMessage
+	if je.LocalizedMessage != nil {
+		loc = &localizedMessage{
+			locale:  je.LocalizedMessage.Locale,
+			message: je.LocalizedMessage.Message,
+			fields:  je.LocalizedFields,
+		}
+	}
+
+	callers, rawTrace := receivedTrace(je.Trace, je.Frames)
+
+	var created time.Time
+	if je.Created != nil {
+		created = *je.Created
+	}
+
+	e := &errorX{
+		code:     CanonicalCode(je.Code),
+		msg:      je.Message,
+		template: je.Template,
+		params:   je.Params,
+		type_:    t,
+		fields:   fields,
+		details:  make(D),
+		callers:  callers,
+		origin:   errors.New(je.Message),
+		rawTrace: rawTrace,
+		created:  created,
+		hops:     je.Hops,
+
+		retryable: je.Retryable,
+		temporary: je.Temporary,
+		traceID:   je.TraceID,
+		spanID:    je.SpanID,
+		localized: loc,
+	}
+	e.addHop(HopReceive)
+	return e
+}
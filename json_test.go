@@ -0,0 +1,66 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	t.Run("encode and decode ErrorX", func(t *testing.T) {
+		err := errx.New("invalid input",
+			errx.WithCode("INVALID_INPUT"),
+			errx.WithType(errx.T_Validation),
+			errx.WithFields(errx.M{"email": "invalid format"}),
+		)
+
+		data, merr := json.Marshal(err)
+		if merr != nil {
+			t.Fatalf("unexpected marshal error: %v", merr)
+		}
+
+		ok, decoded := errx.FromJSON(data)
+		if !ok {
+			t.Fatalf("expected successful conversion")
+		}
+		e := decoded.(errx.ErrorX)
+		if e.Code() != "INVALID_INPUT" || e.Type() != errx.T_Validation {
+			t.Errorf("unexpected code or type: %v, %v", e.Code(), e.Type())
+		}
+		if e.Error() != "Invalid request" {
+			t.Errorf("unexpected message: %v", e.Error())
+		}
+		if e.Fields()["email"] != "invalid format" {
+			t.Errorf("unexpected fields: %v", e.Fields())
+		}
+		if !contains(e.Trace(), "json_test.go") {
+			t.Errorf("expected trace to be carried, got: %v", e.Trace())
+		}
+	})
+}
+
+func TestFromJSON(t *testing.T) {
+	t.Run("invalid data", func(t *testing.T) {
+		ok, err := errx.FromJSON([]byte("not json"))
+		if ok {
+			t.Errorf("expected unsuccessful conversion")
+		}
+		if errx.GetType(err) != errx.T_Internal || errx.GetCode(err) != errx.DefaultCode {
+			t.Errorf("expected default error, got: %v", err)
+		}
+	})
+
+	t.Run("apply options", func(t *testing.T) {
+		ok, err := errx.FromJSON([]byte(`{"code":"X","message":"m","type":"T_Conflict"}`), errx.WithTracePrefix("svc"))
+		if !ok {
+			t.Errorf("expected successful conversion")
+		}
+		if errx.GetType(err) != errx.T_Conflict {
+			t.Errorf("expected type T_Conflict, got %v", errx.GetType(err))
+		}
+		if !contains(err.(errx.ErrorX).Trace(), ">>> svc >>>") {
+			t.Errorf("expected trace prefix, got: %v", err.(errx.ErrorX).Trace())
+		}
+	})
+}
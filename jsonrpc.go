@@ -0,0 +1,133 @@
+package errx
+
+import (
+	"encoding/json"
+	"maps"
+)
+
+// Reserved JSON-RPC 2.0 error codes, as defined by the specification.
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+)
+
+// jsonRPCCodes maps error types to JSON-RPC 2.0 error codes (see JSONRPCCodes).
+var jsonRPCCodes = typeTable[int]{defaults: map[Type]int{
+	T_Internal:           JSONRPCInternalError,
+	T_Validation:         JSONRPCInvalidParams,
+	T_NotFound:           -32004,
+	T_Conflict:           -32009,
+	T_Authentication:     -32001,
+	T_Forbidden:          -32003,
+	T_Throttling:         -32029,
+	T_Unavailable:        -32053,
+	T_Timeout:            -32054,
+	T_Canceled:           -32049,
+	T_PreconditionFailed: -32012,
+	T_Unimplemented:      -32051,
+	T_TooLarge:           -32013,
+}}
+
+// JSONRPCCodes returns a copy of the table mapping error types to JSON-RPC 2.0 error codes.
+//
+// By default, validation and internal errors use the reserved codes of the specification,
+// the other types use codes from the -32000 to -32099 range reserved for implementation-defined server errors.
+// Types missing from the table are mapped to JSONRPCInternalError.
+func JSONRPCCodes() map[Type]int {
+	return maps.Clone(jsonRPCCodes.get())
+}
+
+// SetJSONRPCCodes replaces the table mapping error types to JSON-RPC 2.0 error codes,
+// for example to match the conventions of an existing API:
+//
+//	codes := errx.JSONRPCCodes()
+//	codes[errx.T_NotFound] = -32044
+//	errx.SetJSONRPCCodes(codes)
+//
+// The map is copied. A nil map restores the default table.
+func SetJSONRPCCodes(codes map[Type]int) {
+	jsonRPCCodes.set(codes)
+}
+
+// JSONRPCError is a JSON-RPC 2.0 error object.
+type JSONRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (je *JSONRPCError) Error() string {
+	return je.Message
+}
+
+// ToJSONRPCError converts an error into a JSON-RPC 2.0 error object.
+//
+// The integer code is taken from the JSONRPCCodes table based on the error type.
+// The error code, type name, validation fields and trace are carried in the data member.
+// If the provided error does not implement the ErrorX interface, it is wrapped
+// into a default ErrorX instance.
+//
+// If the error is nil, nil is returned.
+func ToJSONRPCError(err error) *JSONRPCError {
+	if err == nil {
+		return nil
+	}
+
+	e := errorXOf(err)
+
+	return &JSONRPCError{
+		Code:    jsonRPCCode(e.Type()),
+		Message: e.PublicMessage(),
+		Data:    toJSON(e),
+	}
+}
+
+// FromJSONRPCError converts a JSON-RPC 2.0 error object into a custom error (ErrorX).
+//
+// It is intended for use on the client side after making JSON-RPC calls.
+// The function returns a boolean indicating whether the error was converted from ErrorX data (`true`) or not (`false`).
+// If the data member does not hold ErrorX data, the type is derived from the integer code
+// using the JSONRPCCodes table. When several types share a code, the one with the lowest value is used.
+//
+// If the error object is nil, no action is taken, and the function returns `false, nil`.
+// Optional modifications can be applied via OptionFunc.
+func FromJSONRPCError(je *JSONRPCError, opts ...OptionFunc) (bool, error) {
+	if je == nil {
+		return false, nil
+	}
+
+	if je.Data != nil {
+		var data jsonErrorX
+		raw, err := json.Marshal(je.Data)
+		if err == nil && json.Unmarshal(raw, &data) == nil && data.Code != "" {
+			e := fromJSON(&data)
+			e.addTrace(2)
+			applyOpts(e, opts)
+			return true, e
+		}
+	}
+
+	e := newDefault(je.Message)
+	e.type_ = jsonRPCType(je.Code)
+	e.addTrace(2)
+	applyOpts(e, opts)
+	return false, e
+}
+
+// jsonRPCCode returns the JSON-RPC error code for the given type.
+func jsonRPCCode(t Type) int {
+	if code, ok := jsonRPCCodes.get()[t]; ok {
+		return code
+	}
+	return JSONRPCInternalError
+}
+
+// jsonRPCType returns the lowest type mapped to the given JSON-RPC error code.
+func jsonRPCType(code int) Type {
+	t, _ := lookup(jsonRPCCodes.get(), code)
+	return t
+}
@@ -0,0 +1,130 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestToJSONRPCError(t *testing.T) {
+	t.Run("convert types to codes", func(t *testing.T) {
+		testCases := []struct {
+			errType  errx.Type
+			expected int
+		}{
+			{errx.T_Internal, errx.JSONRPCInternalError},
+			{errx.T_Validation, errx.JSONRPCInvalidParams},
+			{errx.T_NotFound, -32004},
+			{errx.Type(99), errx.JSONRPCInternalError},
+		}
+
+		for _, tc := range testCases {
+			je := errx.ToJSONRPCError(errx.New("test error", errx.WithType(tc.errType)))
+			if je.Code != tc.expected {
+				t.Errorf("for error type %v, expected code %d, got %d", tc.errType, tc.expected, je.Code)
+			}
+		}
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		if je := errx.ToJSONRPCError(nil); je != nil {
+			t.Errorf("expected nil, got %v", je)
+		}
+	})
+
+	t.Run("regular error", func(t *testing.T) {
+		je := errx.ToJSONRPCError(errors.New("regular error"))
+		if je.Code != errx.JSONRPCInternalError || je.Message != "Internal server error" {
+			t.Errorf("unexpected error object: %+v", je)
+		}
+	})
+}
+
+func TestFromJSONRPCError(t *testing.T) {
+	t.Run("round trip through JSON", func(t *testing.T) {
+		src := errx.New("invalid input",
+			errx.WithCode("INVALID_INPUT"),
+			errx.WithType(errx.T_Validation),
+			errx.WithFields(errx.M{"email": "invalid format"}),
+		)
+		data, _ := json.Marshal(errx.ToJSONRPCError(src))
+
+		var je errx.JSONRPCError
+		if err := json.Unmarshal(data, &je); err != nil {
+			t.Fatalf("unexpected unmarshal error: %v", err)
+		}
+
+		ok, err := errx.FromJSONRPCError(&je)
+		if !ok {
+			t.Fatalf("expected successful conversion")
+		}
+		e := err.(errx.ErrorX)
+		if e.Code() != "INVALID_INPUT" || e.Type() != errx.T_Validation {
+			t.Errorf("unexpected code or type: %v, %v", e.Code(), e.Type())
+		}
+		if e.Fields()["email"] != "invalid format" {
+			t.Errorf("unexpected fields: %v", e.Fields())
+		}
+		if !contains(e.Trace(), "jsonrpc_test.go") {
+			t.Errorf("expected trace to be carried, got: %v", e.Trace())
+		}
+	})
+
+	t.Run("foreign error object uses code table", func(t *testing.T) {
+		ok, err := errx.FromJSONRPCError(&errx.JSONRPCError{Code: -32001, Message: "unauthenticated", Data: "opaque"})
+		if ok {
+			t.Errorf("expected unsuccessful conversion")
+		}
+		if errx.GetType(err) != errx.T_Authentication || errx.GetCode(err) != errx.DefaultCode {
+			t.Errorf("unexpected type or code: %v, %v", errx.GetType(err), errx.GetCode(err))
+		}
+	})
+
+	t.Run("custom code table", func(t *testing.T) {
+		codes := errx.JSONRPCCodes()
+		codes[errx.T_NotFound] = -32044
+		errx.SetJSONRPCCodes(codes)
+		defer errx.SetJSONRPCCodes(nil)
+
+		je := errx.ToJSONRPCError(errx.New("missing", errx.WithType(errx.T_NotFound)))
+		if je.Code != -32044 {
+			t.Errorf("expected custom code, got %d", je.Code)
+		}
+		_, err := errx.FromJSONRPCError(&errx.JSONRPCError{Code: -32044, Message: "missing"})
+		if errx.GetType(err) != errx.T_NotFound {
+			t.Errorf("expected type T_NotFound, got %v", errx.GetType(err))
+		}
+	})
+
+	t.Run("shared code decodes to the lowest type", func(t *testing.T) {
+		codes := errx.JSONRPCCodes()
+		codes[errx.T_Timeout] = -32053
+		codes[errx.T_Unavailable] = -32053
+		errx.SetJSONRPCCodes(codes)
+		defer errx.SetJSONRPCCodes(nil)
+
+		for range 20 {
+			_, err := errx.FromJSONRPCError(&errx.JSONRPCError{Code: -32053, Message: "down"})
+			if want := min(errx.T_Timeout, errx.T_Unavailable); errx.GetType(err) != want {
+				t.Fatalf("expected type %v, got %v", want, errx.GetType(err))
+			}
+		}
+	})
+
+	t.Run("table is copied", func(t *testing.T) {
+		codes := errx.JSONRPCCodes()
+		codes[errx.T_NotFound] = 1
+		if errx.JSONRPCCodes()[errx.T_NotFound] == 1 {
+			t.Errorf("expected the returned table to be a copy")
+		}
+	})
+
+	t.Run("nil error object", func(t *testing.T) {
+		ok, err := errx.FromJSONRPCError(nil)
+		if ok || err != nil {
+			t.Errorf("expected false, nil; got %v, %v", ok, err)
+		}
+	})
+}
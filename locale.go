@@ -0,0 +1,449 @@
+package errx
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/yaml.v3"
+)
+
+// PluralParam is the template parameter used to select the plural form of a message.
+const PluralParam = "count"
+
+// LocaleMessages holds the messages of a single locale.
+//
+// Codes are keyed by error code, rules are keyed by validation rule,
+// as used in the values of the error fields (see WithFields).
+// Messages are templates with "{name}" placeholders (see WithTemplate).
+//
+// In YAML or JSON files, a message is either a string or a map of plural forms:
+//
+//	codes:
+//	  USER_NOT_FOUND: "Foydalanuvchi {user_id} topilmadi"
+//	  CART_LIMIT:
+//	    one: "Savatda {count} ta mahsulot bo'lishi mumkin"
+//	    other: "Savatda {count} ta mahsulot bo'lishi mumkin"
+//	rules:
+//	  required: "Majburiy maydon"
+type LocaleMessages struct {
+	Codes map[string]Message `yaml:"codes" json:"codes"`
+	Rules map[string]Message `yaml:"rules" json:"rules"`
+}
+
+// Message is a localized message template, optionally with plural forms.
+//
+// Plural forms are keyed by CLDR plural category ("zero", "one", "two", "few", "many", "other")
+// and selected by the PluralParam parameter. Text is used when no plural form matches.
+type Message struct {
+	Text   string
+	Plural map[string]string
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+// A scalar is read as Text, a mapping as Plural.
+func (m *Message) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&m.Text)
+	}
+	if err := value.Decode(&m.Plural); err != nil {
+		return err
+	}
+	m.Text = m.Plural["other"]
+	return nil
+}
+
+// Localizer translates error messages and validation fields into the user's language.
+//
+// Messages are looked up along a fallback chain: the requested locale, its parents
+// obtained by removing subtags ("uz-Latn-UZ", "uz-Latn", "uz"), and finally the default locale.
+type Localizer struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	locales       map[string]localeEntry
+}
+
+// localeEntry is a loaded locale with its name as registered.
+type localeEntry struct {
+	name     string
+	messages LocaleMessages
+}
+
+// localizedMessage is a message translated by a Localizer and attached to an error.
+type localizedMessage struct {
+	locale  string
+	message string
+	fields  M
+}
+
+// NewLocalizer creates a new Localizer with the given default locale,
+// which ends every fallback chain.
+func NewLocalizer(defaultLocale string) *Localizer {
+	return &Localizer{
+		defaultLocale: defaultLocale,
+		locales:       make(map[string]localeEntry),
+	}
+}
+
+// Add adds messages for the given locale.
+// Messages of a locale that is already loaded are merged, new messages win.
+func (l *Localizer) Add(locale string, messages LocaleMessages) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := normalizeLocale(locale)
+	entry, ok := l.locales[key]
+	if !ok {
+		entry = localeEntry{name: locale, messages: LocaleMessages{
+			Codes: make(map[string]Message),
+			Rules: make(map[string]Message),
+		}}
+	}
+	for k, v := range messages.Codes {
+		entry.messages.Codes[k] = v
+	}
+	for k, v := range messages.Rules {
+		entry.messages.Rules[k] = v
+	}
+	l.locales[key] = entry
+}
+
+// Load adds messages for the given locale from YAML or JSON data.
+func (l *Localizer) Load(locale string, data []byte) error {
+	var messages LocaleMessages
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("errx: parse messages for locale %q: %w", locale, err)
+	}
+	l.Add(locale, messages)
+	return nil
+}
+
+// LoadFS loads all message files of a directory.
+// Files must be named after their locale, e.g. "uz.yaml", "ru.yml" or "en-US.json".
+// It is intended for use with embed.FS.
+func (l *Localizer) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("errx: read locale directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("errx: read locale file: %w", err)
+		}
+		if err := l.Load(strings.TrimSuffix(entry.Name(), ext), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locales returns the loaded locales sorted by name.
+func (l *Localizer) Locales() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	locales := make([]string, 0, len(l.locales))
+	for _, entry := range l.locales {
+		locales = append(locales, entry.name)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// Negotiate returns the best loaded locale for the given Accept-Language header values.
+// If none of the requested locales or their parents is loaded, the default locale is returned.
+func (l *Localizer) Negotiate(acceptLanguage ...string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		for _, key := range localeChain(tag) {
+			if entry, ok := l.locales[key]; ok {
+				return entry.name
+			}
+		}
+	}
+	return l.defaultLocale
+}
+
+// NegotiateHTTP returns the best loaded locale for the Accept-Language header of the request.
+func (l *Localizer) NegotiateHTTP(r *http.Request) string {
+	return l.Negotiate(r.Header.Values("Accept-Language")...)
+}
+
+// NegotiateGRPC returns the best loaded locale for the "accept-language" key
+// of the incoming gRPC metadata.
+func (l *Localizer) NegotiateGRPC(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return l.Negotiate(md.Get("accept-language")...)
+}
+
+// Localize returns a copy of the error with a message and fields translated into the given locale.
+//
+// The message is looked up by error code and rendered with the template parameters of the error
+// (see WithTemplate). Field values are looked up as validation rules.
+// The translation is sent by the boundary encoders next to the original message:
+// as a google.rpc.LocalizedMessage by ToGRPCError, and as "localized_message" by the JSON encoder.
+//
+// If no translation is found, the error is returned unchanged.
+// If the error is nil, nil is returned.
+func (l *Localizer) Localize(err error, locale string) error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := err.(*errorX)
+	if !ok {
+		e = wrapFromError(err)
+	}
+
+	loc, found := l.localize(e, locale)
+	if !found {
+		return err
+	}
+
+	e = e.clone()
+	e.localized = loc
+	return e
+}
+
+// WriteHTTPError writes the error like WriteHTTPError, translated into the locale
+// negotiated from the Accept-Language header. It can be used as an HTTPErrorWriter.
+func (l *Localizer) WriteHTTPError(w http.ResponseWriter, r *http.Request, err error) {
+	WriteHTTPError(w, r, l.Localize(err, l.NegotiateHTTP(r)))
+}
+
+// ToGRPCError converts the error like ToGRPCError, translated into the locale
+// negotiated from the incoming gRPC metadata.
+func (l *Localizer) ToGRPCError(ctx context.Context, err error, opts ...OptionFunc) error {
+	if err == nil {
+		return nil
+	}
+
+	e := errorXOf(l.Localize(err, l.NegotiateGRPC(ctx))).clone()
+	e.addTrace(2)
+	e.addHop(HopSend)
+	applyOpts(e, opts)
+
+	return toGRPCStatusError(e)
+}
+
+// GetLocalizedMessage returns the translation attached to the error by a Localizer,
+// either directly or on the server side before the error was sent (see FromGRPCError and FromJSON).
+// The boolean result reports whether the error carries a translation.
+func GetLocalizedMessage(err error) (locale, message string, ok bool) {
+	e, ok := Find[*errorX](err)
+	if !ok || e.localized == nil {
+		return "", "", false
+	}
+	return e.localized.locale, e.localized.message, true
+}
+
+// GetLocalizedFields returns the translated validation fields attached to the error by a Localizer.
+func GetLocalizedFields(err error) M {
+	e, ok := Find[*errorX](err)
+	if !ok || e.localized == nil {
+		return nil
+	}
+	return e.localized.fields
+}
+
+// localize translates the message and fields of the error.
+// The boolean result reports whether any translation was found.
+func (l *Localizer) localize(e *errorX, locale string) (*localizedMessage, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	chain := localeChain(locale)
+	if locale != l.defaultLocale {
+		chain = append(chain, localeChain(l.defaultLocale)...)
+	}
+
+	loc := &localizedMessage{}
+	found := false
+
+	for _, key := range chain {
+		entry, ok := l.locales[key]
+		if !ok {
+			continue
+		}
+		if msg, ok := entry.messages.Codes[e.code]; ok {
+			loc.locale = entry.name
+			loc.message = renderTemplate(msg.pick(entry.name, e.params), e.params)
+			found = true
+			break
+		}
+	}
+
+	for field, rule := range e.fields {
+		for _, key := range chain {
+			entry, ok := l.locales[key]
+			if !ok {
+				continue
+			}
+			if msg, ok := entry.messages.Rules[rule]; ok {
+				if loc.fields == nil {
+					loc.fields = make(M)
+				}
+				if loc.locale == "" {
+					loc.locale = entry.name
+				}
+				loc.fields[field] = renderTemplate(msg.pick(entry.name, e.params), e.params)
+				found = true
+				break
+			}
+		}
+	}
+
+	if found && loc.message == "" {
+		loc.message = e.PublicMessage()
+	}
+	return loc, found
+}
+
+// pick returns the text of the message for the plural category of the PluralParam parameter.
+func (m Message) pick(locale string, params P) string {
+	if len(m.Plural) == 0 {
+		return m.Text
+	}
+
+	n, ok := pluralCount(params[PluralParam])
+	if !ok {
+		return m.Text
+	}
+	if text, ok := m.Plural[pluralCategory(locale, n)]; ok {
+		return text
+	}
+	return m.Text
+}
+
+// pluralCount converts a parameter value to a number.
+func pluralCount(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// pluralCategory returns the CLDR plural category of n for the language of the locale.
+// Languages without dedicated rules use the English rules.
+func pluralCategory(locale string, n float64) string {
+	lang, _, _ := strings.Cut(normalizeLocale(locale), "-")
+
+	if n != math.Trunc(n) {
+		return "other"
+	}
+	i := int64(math.Abs(n))
+
+	switch lang {
+	case "ru", "uk", "be":
+		switch {
+		case i%10 == 1 && i%100 != 11:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if i == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+// normalizeLocale returns the lookup key of a locale: lower case with "-" separators.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// localeChain returns the lookup keys of a locale and its parents,
+// e.g. "uz-latn-uz", "uz-latn", "uz".
+func localeChain(locale string) []string {
+	key := normalizeLocale(locale)
+	if key == "" {
+		return nil
+	}
+
+	chain := []string{key}
+	for {
+		i := strings.LastIndex(key, "-")
+		if i < 0 {
+			return chain
+		}
+		key = key[:i]
+		chain = append(chain, key)
+	}
+}
+
+// parseAcceptLanguage returns the language tags of Accept-Language header values,
+// ordered by decreasing quality.
+func parseAcceptLanguage(values []string) []string {
+	type tag struct {
+		name string
+		q    float64
+	}
+
+	var tags []tag
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if name == "" || name == "*" {
+				continue
+			}
+			q := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+			if q > 0 {
+				tags = append(tags, tag{name: name, q: q})
+			}
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.name
+	}
+	return names
+}
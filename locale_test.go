@@ -0,0 +1,232 @@
+package errx_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/code19m/errx"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLocalizer(t *testing.T) *errx.Localizer {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"locales/en.yaml": {Data: []byte(`
+codes:
+  USER_NOT_FOUND: "User {user_id} not found"
+  CART_LIMIT:
+    one: "Your cart can hold {count} item"
+    other: "Your cart can hold {count} items"
+rules:
+  required: "This field is required"
+`)},
+		"locales/uz.yaml": {Data: []byte(`
+codes:
+  USER_NOT_FOUND: "Foydalanuvchi {user_id} topilmadi"
+rules:
+  required: "Majburiy maydon"
+`)},
+		"locales/ru.json": {Data: []byte(`{
+  "codes": {
+    "CART_LIMIT": {"one": "В корзине может быть {count} товар", "few": "В корзине может быть {count} товара", "many": "В корзине может быть {count} товаров"}
+  }
+}`)},
+		"locales/README.md": {Data: []byte("ignored")},
+	}
+
+	l := errx.NewLocalizer("en")
+	if err := l.LoadFS(fsys, "locales"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return l
+}
+
+func TestLocalizer(t *testing.T) {
+	l := newTestLocalizer(t)
+
+	t.Run("loaded locales", func(t *testing.T) {
+		if got := l.Locales(); !slices.Equal(got, []string{"en", "ru", "uz"}) {
+			t.Errorf("unexpected locales: %v", got)
+		}
+	})
+
+	t.Run("localize message with params", func(t *testing.T) {
+		src := errx.New("", errx.WithCode("USER_NOT_FOUND"), errx.WithTemplate("user {user_id} not found", errx.P{"user_id": 42}))
+
+		err := l.Localize(src, "uz")
+		locale, msg, ok := errx.GetLocalizedMessage(err)
+		if !ok || locale != "uz" || msg != "Foydalanuvchi 42 topilmadi" {
+			t.Errorf("unexpected translation: %v %v %v", locale, msg, ok)
+		}
+		if err.Error() != "user 42 not found" {
+			t.Errorf("expected original message to be kept, got: %v", err.Error())
+		}
+		if _, _, ok := errx.GetLocalizedMessage(src); ok {
+			t.Errorf("expected source error to be unchanged")
+		}
+	})
+
+	t.Run("plural forms", func(t *testing.T) {
+		tests := []struct {
+			locale string
+			count  int
+			want   string
+		}{
+			{"en", 1, "Your cart can hold 1 item"},
+			{"en", 5, "Your cart can hold 5 items"},
+			{"ru", 1, "В корзине может быть 1 товар"},
+			{"ru", 3, "В корзине может быть 3 товара"},
+			{"ru", 11, "В корзине может быть 11 товаров"},
+			{"ru", 22, "В корзине может быть 22 товара"},
+		}
+		for _, tt := range tests {
+			src := errx.New("", errx.WithCode("CART_LIMIT"), errx.WithParams(errx.P{"count": tt.count}))
+			_, msg, _ := errx.GetLocalizedMessage(l.Localize(src, tt.locale))
+			if msg != tt.want {
+				t.Errorf("%s/%d: expected %q, got %q", tt.locale, tt.count, tt.want, msg)
+			}
+		}
+	})
+
+	t.Run("fallback chain", func(t *testing.T) {
+		src := errx.New("user not found", errx.WithCode("USER_NOT_FOUND"), errx.WithParams(errx.P{"user_id": 1}))
+
+		locale, msg, _ := errx.GetLocalizedMessage(l.Localize(src, "uz-Latn-UZ"))
+		if locale != "uz" || msg != "Foydalanuvchi 1 topilmadi" {
+			t.Errorf("expected parent locale, got: %v %v", locale, msg)
+		}
+
+		locale, msg, _ = errx.GetLocalizedMessage(l.Localize(src, "ru"))
+		if locale != "en" || msg != "User 1 not found" {
+			t.Errorf("expected default locale, got: %v %v", locale, msg)
+		}
+	})
+
+	t.Run("localize fields", func(t *testing.T) {
+		src := errx.New("invalid input", errx.WithCode("BAD_INPUT"), errx.WithFields(errx.M{"name": "required", "age": "min"}))
+
+		err := l.Localize(src, "uz")
+		fields := errx.GetLocalizedFields(err)
+		if fields["name"] != "Majburiy maydon" || len(fields) != 1 {
+			t.Errorf("unexpected fields: %v", fields)
+		}
+		if _, msg, _ := errx.GetLocalizedMessage(err); msg != "Internal server error" {
+			t.Errorf("expected public message as fallback, got: %v", msg)
+		}
+	})
+
+	t.Run("no translation", func(t *testing.T) {
+		src := errx.New("boom", errx.WithCode("UNKNOWN_CODE"))
+		if err := l.Localize(src, "uz"); err != src {
+			t.Errorf("expected error to be returned unchanged")
+		}
+		if l.Localize(nil, "uz") != nil {
+			t.Errorf("expected nil")
+		}
+	})
+}
+
+func TestLocalizerNegotiate(t *testing.T) {
+	l := newTestLocalizer(t)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"uz-UZ,ru;q=0.8,en;q=0.5", "uz"},
+		{"de-DE, ru;q=0.9, uz;q=0.3", "ru"},
+		{"en;q=0.1, RU_ru;q=0.9", "ru"},
+		{"de, fr;q=0.5", "en"},
+		{"uz;q=0, ru", "ru"},
+		{"", "en"},
+	}
+	for _, tt := range tests {
+		if got := l.Negotiate(tt.header); got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestLocalizerWriteHTTPError(t *testing.T) {
+	l := newTestLocalizer(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	r.Header.Set("Accept-Language", "uz")
+	w := httptest.NewRecorder()
+
+	src := errx.New("", errx.WithCode("USER_NOT_FOUND"), errx.WithType(errx.T_NotFound),
+		errx.WithTemplate("user {user_id} not found", errx.P{"user_id": 42}))
+	l.WriteHTTPError(w, r, src)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status: %v", w.Code)
+	}
+
+	var body struct {
+		Message          string `json:"message"`
+		LocalizedMessage struct {
+			Locale  string `json:"locale"`
+			Message string `json:"message"`
+		} `json:"localized_message"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.Message != "Resource not found" {
+		t.Errorf("unexpected message: %v", body.Message)
+	}
+	if body.LocalizedMessage.Locale != "uz" || body.LocalizedMessage.Message != "Foydalanuvchi 42 topilmadi" {
+		t.Errorf("unexpected localized message: %+v", body.LocalizedMessage)
+	}
+
+	_, err := errx.FromJSON(w.Body.Bytes())
+	if _, msg, _ := errx.GetLocalizedMessage(err); msg != "Foydalanuvchi 42 topilmadi" {
+		t.Errorf("expected localized message to survive decoding, got: %v", msg)
+	}
+}
+
+func TestLocalizerToGRPCError(t *testing.T) {
+	l := newTestLocalizer(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "uz-UZ, en;q=0.5"))
+
+	src := errx.New("invalid input", errx.WithCode("USER_NOT_FOUND"), errx.WithType(errx.T_Validation),
+		errx.WithParams(errx.P{"user_id": 7}), errx.WithFields(errx.M{"name": "required"}))
+	grpcErr := l.ToGRPCError(ctx, src)
+
+	st, _ := status.FromError(grpcErr)
+	var lm *errdetails.LocalizedMessage
+	var br *errdetails.BadRequest
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.LocalizedMessage:
+			lm = d
+		case *errdetails.BadRequest:
+			br = d
+		}
+	}
+	if lm == nil || lm.GetLocale() != "uz" || lm.GetMessage() != "Foydalanuvchi 7 topilmadi" {
+		t.Errorf("unexpected localized message detail: %v", lm)
+	}
+	if br == nil || len(br.GetFieldViolations()) != 1 || br.GetFieldViolations()[0].GetDescription() != "Majburiy maydon" {
+		t.Errorf("unexpected bad request detail: %v", br)
+	}
+
+	ok, err := errx.FromGRPCError(grpcErr)
+	if !ok {
+		t.Errorf("expected ErrorX detail")
+	}
+	if locale, msg, _ := errx.GetLocalizedMessage(err); locale != "uz" || msg != "Foydalanuvchi 7 topilmadi" {
+		t.Errorf("unexpected translation after round trip: %v %v", locale, msg)
+	}
+	if errx.GetLocalizedFields(err)["name"] != "Majburiy maydon" {
+		t.Errorf("unexpected fields after round trip: %v", errx.GetLocalizedFields(err))
+	}
+}
@@ -0,0 +1,138 @@
+package errx
+
+import "strings"
+
+// CodeSeparator separates the segments of hierarchical error codes,
+// such as "billing.invoice.NOT_FOUND".
+const CodeSeparator = "."
+
+// CodeNamespace returns the namespace of a hierarchical code,
+// which is everything before the last separator.
+// For example, the namespace of "billing.invoice.NOT_FOUND" is "billing.invoice".
+// Codes without a namespace return an empty string.
+func CodeNamespace(code string) string {
+	i := strings.LastIndex(code, CodeSeparator)
+	if i < 0 {
+		return ""
+	}
+	return code[:i]
+}
+
+// CodeName returns the last segment of a hierarchical code.
+// For example, the name of "billing.invoice.NOT_FOUND" is "NOT_FOUND".
+func CodeName(code string) string {
+	return code[strings.LastIndex(code, CodeSeparator)+1:]
+}
+
+// HasCodePrefix checks if the error's code is in the given namespace.
+//
+// The prefix is matched on whole segments, so "billing" matches "billing.invoice.NOT_FOUND"
+// and "billing.NOT_FOUND", but not "billingx.NOT_FOUND".
+func HasCodePrefix(err error, prefix string) bool {
+	return codeHasPrefix(GetCode(err), prefix)
+}
+
+// MatchCode reports whether the code matches the pattern.
+//
+// A pattern is a code whose segments may be "*", which matches exactly one segment.
+// A trailing "*" matches one or more segments, so "billing.*" matches every code
+// in the billing namespace, and "*" alone matches every code.
+// Patterns without "*" match only the identical code.
+func MatchCode(code, pattern string) bool {
+	if !strings.Contains(pattern, "*") {
+		return code == pattern
+	}
+
+	codeSegs := strings.Split(code, CodeSeparator)
+	patSegs := strings.Split(pattern, CodeSeparator)
+
+	for i, p := range patSegs {
+		if i == len(patSegs)-1 && p == "*" {
+			return len(codeSegs) > i
+		}
+		if i >= len(codeSegs) || (p != "*" && p != codeSegs[i]) {
+			return false
+		}
+	}
+	return len(codeSegs) == len(patSegs)
+}
+
+// Scope creates errors whose codes are automatically placed in a namespace.
+// It is intended to be created once per service or package:
+//
+//	var billing = errx.NewScope("billing.invoice")
+//
+//	err := billing.New("invoice not found", errx.WithCode("NOT_FOUND")) // code "billing.invoice.NOT_FOUND"
+type Scope struct {
+	namespace string
+}
+
+// NewScope creates a new Scope for the given namespace.
+func NewScope(namespace string) Scope {
+	return Scope{namespace: strings.TrimSuffix(namespace, CodeSeparator)}
+}
+
+// Namespace returns the namespace of the scope.
+func (s Scope) Namespace() string {
+	return s.namespace
+}
+
+// Code returns the code qualified with the scope's namespace.
+// Codes already in the namespace are returned unchanged.
+func (s Scope) Code(name string) string {
+	if s.namespace == "" || codeHasPrefix(name, s.namespace) {
+		return name
+	}
+	return s.namespace + CodeSeparator + name
+}
+
+// New creates a new ErrorX like New, qualifying the code set with WithCode
+// with the scope's namespace.
+func (s Scope) New(msg string, opts ...OptionFunc) error {
+	e := newDefault(msg)
+
+	e.addTrace(2)
+	applyOpts(e, append(opts[:len(opts):len(opts)], s.qualify()))
+	e.captureStack(2)
+
+	return e
+}
+
+// Wrap wraps an error like Wrap, qualifying the code set with WithCode
+// with the scope's namespace.
+func (s Scope) Wrap(err error, opts ...OptionFunc) error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := err.(*errorX)
+	if !ok {
+		e = wrapFromError(err)
+	}
+	e = e.clone()
+
+	e.addTrace(2)
+	e.addHop(HopWrap)
+	applyOpts(e, append(opts[:len(opts):len(opts)], s.qualify()))
+	e.captureStack(2)
+
+	return e
+}
+
+// qualify returns an option adding the namespace to a code set by the preceding options.
+// It must be applied last, so that the catalog sees the qualified code.
+func (s Scope) qualify() OptionFunc {
+	return func(e *errorX) {
+		if e.codeSet {
+			e.code = s.Code(e.code)
+		}
+	}
+}
+
+// codeHasPrefix reports whether the code is in the namespace given by prefix.
+func codeHasPrefix(code, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	return code == prefix || strings.HasPrefix(code, prefix+CodeSeparator)
+}
@@ -0,0 +1,121 @@
+package errx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func init() {
+	errx.Declare(errx.CodeDef{Code: "billing.invoice.PAID", Type: errx.T_PreconditionFailed})
+}
+
+func TestCodeNamespace(t *testing.T) {
+	testCases := []struct {
+		code      string
+		namespace string
+		name      string
+	}{
+		{"billing.invoice.NOT_FOUND", "billing.invoice", "NOT_FOUND"},
+		{"billing.NOT_FOUND", "billing", "NOT_FOUND"},
+		{"NOT_FOUND", "", "NOT_FOUND"},
+	}
+
+	for _, tc := range testCases {
+		if got := errx.CodeNamespace(tc.code); got != tc.namespace {
+			t.Errorf("CodeNamespace(%q) = %q, want %q", tc.code, got, tc.namespace)
+		}
+		if got := errx.CodeName(tc.code); got != tc.name {
+			t.Errorf("CodeName(%q) = %q, want %q", tc.code, got, tc.name)
+		}
+	}
+}
+
+func TestHasCodePrefix(t *testing.T) {
+	err := errx.New("error", errx.WithCode("billing.invoice.NOT_FOUND"))
+
+	if !errx.HasCodePrefix(err, "billing") || !errx.HasCodePrefix(err, "billing.invoice") {
+		t.Errorf("expected code to be in the billing namespace")
+	}
+	if errx.HasCodePrefix(err, "bill") || errx.HasCodePrefix(err, "billing.inv") {
+		t.Errorf("expected prefix to match whole segments only")
+	}
+}
+
+func TestMatchCode(t *testing.T) {
+	testCases := []struct {
+		code     string
+		pattern  string
+		expected bool
+	}{
+		{"billing.invoice.NOT_FOUND", "billing.invoice.NOT_FOUND", true},
+		{"billing.invoice.NOT_FOUND", "billing.*", true},
+		{"billing.invoice.NOT_FOUND", "billing.invoice.*", true},
+		{"billing.invoice.NOT_FOUND", "*.invoice.NOT_FOUND", true},
+		{"billing.invoice.NOT_FOUND", "*.NOT_FOUND", false},
+		{"billing.invoice.NOT_FOUND", "*", true},
+		{"billing", "billing.*", false},
+		{"billingx.NOT_FOUND", "billing.*", false},
+		{"users.NOT_FOUND", "billing.*", false},
+		{"NOT_FOUND", "NOT_FOUND", true},
+	}
+
+	for _, tc := range testCases {
+		if got := errx.MatchCode(tc.code, tc.pattern); got != tc.expected {
+			t.Errorf("MatchCode(%q, %q) = %v, want %v", tc.code, tc.pattern, got, tc.expected)
+		}
+	}
+}
+
+func TestIsCodeInPatterns(t *testing.T) {
+	err := errx.New("error", errx.WithCode("billing.invoice.NOT_FOUND"))
+
+	if !errx.IsCodeIn(err, "users.*", "billing.*") {
+		t.Errorf("expected code to match wildcard pattern")
+	}
+	if errx.IsCodeIn(err, "users.*", "NOT_FOUND") {
+		t.Errorf("expected code not to match")
+	}
+}
+
+func TestScope(t *testing.T) {
+	billing := errx.NewScope("billing.invoice")
+
+	t.Run("new qualifies code", func(t *testing.T) {
+		err := billing.New("invoice not found", errx.WithCode("NOT_FOUND"), errx.WithType(errx.T_NotFound))
+		e := err.(errx.ErrorX)
+		if e.Code() != "billing.invoice.NOT_FOUND" {
+			t.Errorf("unexpected code: %v", e.Code())
+		}
+		if !contains(e.Trace(), "namespace_test.go") {
+			t.Errorf("expected trace to point to the caller, got: %v", e.Trace())
+		}
+	})
+
+	t.Run("already qualified code is unchanged", func(t *testing.T) {
+		if code := billing.Code("billing.invoice.NOT_FOUND"); code != "billing.invoice.NOT_FOUND" {
+			t.Errorf("unexpected code: %v", code)
+		}
+	})
+
+	t.Run("wrap qualifies code", func(t *testing.T) {
+		err := billing.Wrap(errors.New("no rows"), errx.WithCode("NOT_FOUND"))
+		if errx.GetCode(err) != "billing.invoice.NOT_FOUND" {
+			t.Errorf("unexpected code: %v", errx.GetCode(err))
+		}
+	})
+
+	t.Run("wrap keeps foreign code", func(t *testing.T) {
+		src := errx.New("user not found", errx.WithCode("users.NOT_FOUND"))
+		if code := errx.GetCode(billing.Wrap(src)); code != "users.NOT_FOUND" {
+			t.Errorf("unexpected code: %v", code)
+		}
+	})
+
+	t.Run("qualified code uses catalog", func(t *testing.T) {
+		if typ := errx.GetType(billing.New("already paid", errx.WithCode("PAID"))); typ != errx.T_PreconditionFailed {
+			t.Errorf("expected type T_PreconditionFailed, got %v", typ)
+		}
+	})
+}
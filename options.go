@@ -7,9 +7,13 @@ type OptionFunc func(*errorX)
 
 // WithCode sets the error code.
 // If this option is not used, the default code is "INTERNAL".
+//
+// If the code is declared in the catalog (see Declare), its default type
+// and message are used unless WithType or a message is given explicitly.
 func WithCode(code string) OptionFunc {
 	return func(e *errorX) {
 		e.code = code
+		e.codeSet = true
 	}
 }
 
@@ -18,6 +22,7 @@ func WithCode(code string) OptionFunc {
 func WithType(t Type) OptionFunc {
 	return func(e *errorX) {
 		e.type_ = t
+		e.typeSet = true
 	}
 }
 
@@ -25,10 +30,20 @@ func WithType(t Type) OptionFunc {
 // specifically designed for error propagation between microservices,
 // particularly in gRPC communication.
 //
-// The trace is changed in the format ">>> prefix >>> %s".
+// The frames recorded so far that are not yet attributed to a service are attributed to prefix,
+// and the trace is rendered in the format ">>> prefix >>> %s".
 func WithTracePrefix(prefix string) OptionFunc {
 	return func(e *errorX) {
-		e.trace = fmt.Sprintf(">>> %s >>> %s", prefix, e.trace)
+		marked := false
+		for i := range e.callers {
+			if e.callers[i].service == "" {
+				e.callers[i].service = prefix
+				marked = true
+			}
+		}
+		if !marked && len(e.callers) == 0 {
+			e.rawTrace = fmt.Sprintf(">>> %s >>> %s", prefix, e.rawTrace)
+		}
 		if e.details != nil {
 			details := make(D)
 			for k, v := range e.details {
@@ -76,3 +91,60 @@ func WithFields(fields M) OptionFunc {
 		e.fields = fields
 	}
 }
+
+// WithRetryable overrides whether the error is retryable.
+// If this option is not used, the default of the error type is used (see Type.IsRetryable).
+// The override is sent to peers over gRPC and JSON.
+func WithRetryable(retryable bool) OptionFunc {
+	return func(e *errorX) {
+		e.retryable = &retryable
+	}
+}
+
+// WithTemporary overrides whether the error is temporary.
+// If this option is not used, the default of the error type is used (see Type.IsTemporary).
+// The override is sent to peers over gRPC and JSON.
+func WithTemporary(temporary bool) OptionFunc {
+	return func(e *errorX) {
+		e.temporary = &temporary
+	}
+}
+
+// WithTemplate sets a message template with named parameters.
+// The message is rendered lazily from the template when Error is called.
+// The parameters are carried by the gRPC and JSON encoders, so clients can re-render the message
+// themselves from the code and parameters. The template is only sent when the internal message is sent
+// to clients (see PublicMessages), as it is internal too.
+//
+// Placeholders have the form "{name}". The parameters are also recorded in the details,
+// with keys prefixed by ParamDetailPrefix.
+//
+// Example:
+// errx.New("", errx.WithTemplate("user {user_id} not found", errx.P{"user_id": 42}))
+func WithTemplate(template string, params P) OptionFunc {
+	return func(e *errorX) {
+		e.template = template
+		WithParams(params)(e)
+	}
+}
+
+// WithParams adds named parameters for the message template.
+// Existing parameters with the same name are overwritten.
+// It is intended for codes whose template is declared in the catalog (see CodeDef.Message).
+func WithParams(params P) OptionFunc {
+	return func(e *errorX) {
+		if len(params) == 0 {
+			return
+		}
+		if e.params == nil {
+			e.params = make(P)
+		}
+		if e.details == nil {
+			e.details = make(D)
+		}
+		for k, v := range params {
+			e.params[k] = v
+			e.details[ParamDetailPrefix+k] = v
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package errx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TraceHop is the part of a trace recorded in a single service.
+type TraceHop struct {
+	// Service is the service the frames were recorded in, as set by WithTracePrefix.
+	// It is empty for the first hop if its frames were recorded in the service that rendered the trace.
+	Service string
+
+	// Frames are the frames recorded in the service, the most recent first.
+	Frames []Frame
+}
+
+// frameRe matches a rendered frame like "[service.go:12] users.(*Service).Get",
+// optionally followed by the count of a collapsed frame, like " (x3)".
+var frameRe = regexp.MustCompile(`^\[(.+):(\d+)\] (\S+)(?: \(x(\d+)\))?$`)
+
+// omittedRe matches a rendered placeholder frame like "... 7 frames omitted".
+var omittedRe = regexp.MustCompile(`^\.\.\. (\d+) frames omitted$`)
+
+// ParseTrace parses a trace string in the default format, as returned by ErrorX.Trace
+// (with the default renderer) and written to logs:
+//
+//	[handler.go:10] api.Get ➡️ >>> users >>> [client.go:20] users.call ➡️ [server.go:30] users.handle
+//
+// The trace is split into hops at the ">>> service >>> " markers added by WithTracePrefix.
+// Frames hold the file, line and function as rendered; the file's path is set only if
+// the trace was rendered with paths, and the package is derived from the function name.
+// Collapsed and placeholder frames (see TraceFilter) hold their counts.
+//
+// An empty trace returns no hops. A malformed frame returns an error.
+func ParseTrace(trace string) ([]TraceHop, error) {
+	trace = strings.TrimSpace(trace)
+	if trace == "" {
+		return nil, nil
+	}
+
+	hops := []TraceHop{{}}
+	for _, part := range strings.Split(trace, strings.TrimSpace(DefaultTraceSeparator)) {
+		part = strings.TrimSpace(part)
+
+		for {
+			rest, ok := strings.CutPrefix(part, ">>> ")
+			if !ok {
+				break
+			}
+			service, rest, ok := strings.Cut(rest, " >>>")
+			if !ok {
+				return nil, fmt.Errorf("errx: invalid service marker in trace: %q", part)
+			}
+			if last := &hops[len(hops)-1]; len(last.Frames) == 0 && last.Service == "" {
+				last.Service = service
+			} else {
+				hops = append(hops, TraceHop{Service: service})
+			}
+			part = strings.TrimSpace(rest)
+		}
+
+		if part == "" {
+			continue
+		}
+		f, err := parseFrame(part)
+		if err != nil {
+			return nil, err
+		}
+		last := &hops[len(hops)-1]
+		f.Service = last.Service
+		last.Frames = append(last.Frames, f)
+	}
+
+	return hops, nil
+}
+
+// parseFrame parses a rendered frame like "[service.go:12] users.(*Service).Get".
+func parseFrame(s string) (Frame, error) {
+	if m := omittedRe.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return Frame{}, fmt.Errorf("errx: invalid placeholder in trace: %q", s)
+		}
+		return Frame{Omitted: n}, nil
+	}
+
+	m := frameRe.FindStringSubmatch(s)
+	if m == nil {
+		return Frame{}, fmt.Errorf("errx: invalid frame in trace: %q", s)
+	}
+
+	line, err := strconv.Atoi(m[2])
+	if err != nil {
+		return Frame{}, fmt.Errorf("errx: invalid line in trace frame: %q", s)
+	}
+
+	f := Frame{Line: line, Function: m[3], Package: funcPackage(m[3])}
+	if m[4] != "" {
+		if f.Repeat, err = strconv.Atoi(m[4]); err != nil {
+			return Frame{}, fmt.Errorf("errx: invalid count in trace frame: %q", s)
+		}
+	}
+	if dir, file := pathSplit(m[1]); dir != "" {
+		f.File, f.Path = file, m[1]
+	} else {
+		f.File = file
+	}
+	return f, nil
+}
+
+// receivedTrace returns the call sites of a trace received from a peer,
+// as structured frames and rendered as a string.
+//
+// The part of the string not covered by the frames, sent by peers that do not send frames,
+// is parsed with ParseTrace. If it cannot be parsed without loss, it is returned as a raw trace.
+func receivedTrace(trace string, frames []Frame) ([]caller, string) {
+	raw := rawTraceOf(trace, frames)
+
+	hops, err := ParseTrace(raw)
+	if err != nil {
+		return callersOf(frames), raw
+	}
+
+	all := slices.Clip(frames)
+	for _, hop := range hops {
+		if len(hop.Frames) == 0 {
+			return callersOf(frames), raw
+		}
+		all = append(all, hop.Frames...)
+	}
+	return callersOf(all), ""
+}
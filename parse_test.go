@@ -0,0 +1,82 @@
+package errx_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestParseTrace(t *testing.T) {
+	t.Run("hops and frames", func(t *testing.T) {
+		hops, err := errx.ParseTrace("[handler.go:10] api.Get ➡️ >>> users >>> [client.go:20] users.call ➡️ [server.go:30] users.(*Server).handle ➡️ >>> billing >>> [internal/db/db.go:5] github.com/acme/billing/db.Query")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []errx.TraceHop{
+			{Frames: []errx.Frame{
+				{File: "handler.go", Line: 10, Function: "api.Get", Package: "api"},
+			}},
+			{Service: "users", Frames: []errx.Frame{
+				{File: "client.go", Line: 20, Function: "users.call", Package: "users", Service: "users"},
+				{File: "server.go", Line: 30, Function: "users.(*Server).handle", Package: "users", Service: "users"},
+			}},
+			{Service: "billing", Frames: []errx.Frame{
+				{File: "db.go", Path: "internal/db/db.go", Line: 5, Function: "github.com/acme/billing/db.Query", Package: "github.com/acme/billing/db", Service: "billing"},
+			}},
+		}
+		if !reflect.DeepEqual(hops, want) {
+			t.Errorf("expected\n%+v\ngot\n%+v", want, hops)
+		}
+	})
+
+	t.Run("leading and consecutive markers", func(t *testing.T) {
+		hops, err := errx.ParseTrace(">>> gateway >>> >>> users >>> [a.go:1] a.f")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(hops) != 2 || hops[0].Service != "gateway" || len(hops[0].Frames) != 0 || hops[1].Service != "users" || len(hops[1].Frames) != 1 {
+			t.Errorf("unexpected hops: %+v", hops)
+		}
+	})
+
+	t.Run("collapsed and placeholder frames", func(t *testing.T) {
+		hops, err := errx.ParseTrace("[tree.go:4] tree.walk (x3) ➡️ ... 7 frames omitted ➡️ [main.go:1] main.main")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		frames := hops[0].Frames
+		if len(frames) != 3 || frames[0].Repeat != 3 || frames[0].Function != "tree.walk" || frames[1].Omitted != 7 || frames[2].Line != 1 {
+			t.Errorf("unexpected frames: %+v", frames)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		err := errx.Wrap(errx.Wrap(generateErrorThroughChain(), errx.WithTracePrefix("users")))
+		hops, perr := errx.ParseTrace(err.(errx.ErrorX).Trace())
+		if perr != nil {
+			t.Fatalf("unexpected error: %v", perr)
+		}
+
+		frames := errx.GetFrames(err)
+		if len(hops) != 2 || len(hops[0].Frames) != 1 || len(hops[1].Frames) != len(frames)-1 {
+			t.Fatalf("unexpected hops: %+v", hops)
+		}
+		if got := hops[1].Frames[0]; got.Line != frames[1].Line || got.File != frames[1].File || got.Function != frames[1].ShortFunction() {
+			t.Errorf("unexpected frame: %+v", got)
+		}
+	})
+
+	t.Run("empty and malformed", func(t *testing.T) {
+		if hops, err := errx.ParseTrace("  "); err != nil || hops != nil {
+			t.Errorf("expected no hops, got %v %v", hops, err)
+		}
+		if _, err := errx.ParseTrace("[a.go:1] a.f ➡️ not a frame"); err == nil {
+			t.Errorf("expected error for malformed frame")
+		}
+		if _, err := errx.ParseTrace(">>> unterminated [a.go:1] a.f"); err == nil {
+			t.Errorf("expected error for malformed marker")
+		}
+	})
+}
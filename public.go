@@ -0,0 +1,96 @@
+package errx
+
+import "maps"
+
+// publicMessages maps error types to the messages sent to clients (see PublicMessages).
+var publicMessages = typeTable[string]{defaults: map[Type]string{
+	T_Internal:           "Internal server error",
+	T_Validation:         "Invalid request",
+	T_NotFound:           "Resource not found",
+	T_Conflict:           "Resource already exists",
+	T_Authentication:     "Authentication required",
+	T_Forbidden:          "Permission denied",
+	T_Throttling:         "Too many requests",
+	T_Unavailable:        "Service temporarily unavailable",
+	T_Timeout:            "Request timed out",
+	T_Canceled:           "Request canceled",
+	T_PreconditionFailed: "Precondition failed",
+	T_Unimplemented:      "Not implemented",
+	T_TooLarge:           "Request too large",
+}}
+
+// PublicMessages returns a copy of the table of public messages sent to clients for errors of a given type
+// when no message was set with WithPublicMessage.
+//
+// By default, every type has a generic message, so internal details are never sent by accident.
+// Types missing from the table send the internal message returned by Error().
+func PublicMessages() map[Type]string {
+	return maps.Clone(publicMessages.get())
+}
+
+// SetPublicMessages replaces the table of public messages, for example to reword them:
+//
+//	messages := errx.PublicMessages()
+//	messages[errx.T_NotFound] = "Not found"
+//	errx.SetPublicMessages(messages)
+//
+// Sending the internal message is opt-in: types removed from the table send Error(),
+// and an empty map sends it for every type, as earlier versions of this package did.
+//
+// The map is copied. A nil map restores the default table.
+func SetPublicMessages(messages map[Type]string) {
+	publicMessages.set(messages)
+}
+
+// WithPublicMessage sets the message sent to clients by the boundary encoders
+// (ToGRPCError, WriteHTTPError, the JSON encoder and the other protocol adapters).
+// The message may contain template placeholders, which are rendered with the parameters of the error.
+//
+// Error() keeps returning the internal message, which is intended for logging.
+func WithPublicMessage(msg string) OptionFunc {
+	return func(e *errorX) {
+		e.publicMsg = msg
+	}
+}
+
+// GetPublicMessage returns the message of the first ErrorX in the error's chain that is safe to show to clients.
+// It is sent by the boundary encoders instead of Error() (see WithPublicMessage and PublicMessages).
+// If there is none, the public message of the default type is returned,
+// or the error message if there is none either.
+func GetPublicMessage(err error) string {
+	if e, ok := Find[*errorX](err); ok {
+		return e.PublicMessage()
+	}
+	if msg, ok := publicMessages.get()[DefaultType]; ok {
+		return msg
+	}
+	return err.Error()
+}
+
+// PublicMessage returns the message that is safe to show to clients (see GetPublicMessage).
+func (e errorX) PublicMessage() string {
+	if e.publicMsg != "" {
+		return renderTemplate(e.publicMsg, e.params)
+	}
+	if msg, ok := publicMessages.get()[e.type_]; ok {
+		return msg
+	}
+	return e.Error()
+}
+
+// publicTemplate returns the message template and parameters that may be sent to clients.
+// The parameters are always sent, as clients re-render messages from the code and parameters.
+// The internal template is withheld when a public message replaces the internal message,
+// as clients would otherwise re-render the internal message from it.
+func (e *errorX) publicTemplate() (string, P) {
+	if e.publicMsg != "" {
+		if len(e.params) == 0 {
+			return "", nil
+		}
+		return e.publicMsg, e.params
+	}
+	if _, ok := publicMessages.get()[e.type_]; ok {
+		return "", e.params
+	}
+	return e.template, e.params
+}
@@ -0,0 +1,136 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/code19m/errx"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithPublicMessage(t *testing.T) {
+	err := errx.New("pq: duplicate key value violates unique constraint \"users_email_key\"",
+		errx.WithCode("EMAIL_TAKEN"),
+		errx.WithType(errx.T_Conflict),
+		errx.WithPublicMessage("email {email} is already registered"),
+		errx.WithParams(errx.P{"email": "a@b.c"}),
+	)
+
+	t.Run("Error keeps the internal message", func(t *testing.T) {
+		if err.Error() != "pq: duplicate key value violates unique constraint \"users_email_key\"" {
+			t.Errorf("unexpected message: %v", err.Error())
+		}
+		if got := errx.GetPublicMessage(err); got != "email a@b.c is already registered" {
+			t.Errorf("unexpected public message: %v", got)
+		}
+	})
+
+	t.Run("wrapping keeps the public message", func(t *testing.T) {
+		wrapped := errx.Wrap(err, errx.WithDetails(errx.D{"layer": "service"}))
+		if errx.GetPublicMessage(wrapped) != "email a@b.c is already registered" {
+			t.Errorf("unexpected public message: %v", errx.GetPublicMessage(wrapped))
+		}
+	})
+
+	t.Run("gRPC sends the public message", func(t *testing.T) {
+		grpcErr := errx.ToGRPCError(err)
+		st, _ := status.FromError(grpcErr)
+		if st.Message() != "email a@b.c is already registered" {
+			t.Errorf("unexpected status message: %v", st.Message())
+		}
+
+		_, got := errx.FromGRPCError(grpcErr)
+		e := got.(errx.ErrorX)
+		if e.Error() != "email a@b.c is already registered" || errx.GetTemplate(e) != "email {email} is already registered" {
+			t.Errorf("unexpected decoded error: %v %v", e.Error(), errx.GetTemplate(e))
+		}
+	})
+
+	t.Run("HTTP sends the public message", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		errx.WriteHTTPError(w, httptest.NewRequest(http.MethodPost, "/users", nil), err)
+
+		var body struct {
+			Message string `json:"message"`
+		}
+		if jerr := json.Unmarshal(w.Body.Bytes(), &body); jerr != nil {
+			t.Fatalf("unexpected error: %v", jerr)
+		}
+		if body.Message != "email a@b.c is already registered" {
+			t.Errorf("unexpected message: %v", body.Message)
+		}
+	})
+}
+
+func TestPublicMessages(t *testing.T) {
+	t.Run("default by type", func(t *testing.T) {
+		err := errx.Wrap(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+		if errx.GetPublicMessage(err) != "Internal server error" {
+			t.Errorf("unexpected public message: %v", errx.GetPublicMessage(err))
+		}
+		if errx.GetPublicMessage(errors.New("plain")) != "Internal server error" {
+			t.Errorf("expected default type message for plain errors")
+		}
+
+		data, _ := json.Marshal(err)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		if body["message"] != "Internal server error" {
+			t.Errorf("unexpected JSON message: %v", body["message"])
+		}
+	})
+
+	t.Run("internal template is withheld, params are sent", func(t *testing.T) {
+		err := errx.New("", errx.WithTemplate("query on {table} failed: {reason}", errx.P{"table": "users", "reason": "timeout"}))
+
+		je := errx.ToJSONRPCError(err)
+		data, _ := json.Marshal(je)
+		var body struct {
+			Message string `json:"message"`
+			Data    struct {
+				Template string `json:"template"`
+				Params   errx.P `json:"params"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(data, &body)
+		if body.Message != "Internal server error" || body.Data.Template != "" || body.Data.Params["table"] != "users" {
+			t.Errorf("expected internal template to be withheld and params sent, got: %s", data)
+		}
+	})
+
+	t.Run("explicit public message wins", func(t *testing.T) {
+		err := errx.New("upstream 503", errx.WithType(errx.T_Unavailable), errx.WithPublicMessage("Payments are down, try later"))
+		if errx.GetPublicMessage(err) != "Payments are down, try later" {
+			t.Errorf("unexpected public message: %v", errx.GetPublicMessage(err))
+		}
+	})
+
+	t.Run("internal message is opt-in", func(t *testing.T) {
+		messages := errx.PublicMessages()
+		delete(messages, errx.T_NotFound)
+		errx.SetPublicMessages(messages)
+		defer errx.SetPublicMessages(nil)
+
+		if msg := errx.GetPublicMessage(errx.New("user 42 not found", errx.WithType(errx.T_NotFound))); msg != "user 42 not found" {
+			t.Errorf("expected internal message for type without entry, got: %v", msg)
+		}
+		if msg := errx.GetPublicMessage(errx.New("db down")); msg != "Internal server error" {
+			t.Errorf("expected generic message for other types, got: %v", msg)
+		}
+
+		errx.SetPublicMessages(map[errx.Type]string{})
+		if msg := errx.GetPublicMessage(errx.New("db down")); msg != "db down" {
+			t.Errorf("expected internal message for every type, got: %v", msg)
+		}
+	})
+
+	t.Run("table is copied", func(t *testing.T) {
+		errx.PublicMessages()[errx.T_Internal] = "changed"
+		if msg := errx.GetPublicMessage(errx.New("db down")); msg != "Internal server error" {
+			t.Errorf("expected table to be unaffected, got: %v", msg)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package errx
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PathStyle controls how the source file of a frame is rendered.
+type PathStyle int
+
+const (
+	// PathBase renders the base name of the file, e.g. "service.go".
+	PathBase PathStyle = iota
+
+	// PathRelative renders the file relative to the main module, e.g. "internal/users/service.go".
+	// Files of other modules are rendered with their package import path,
+	// e.g. "google.golang.org/grpc/server.go".
+	PathRelative
+
+	// PathAbsolute renders the full path of the file as recorded by the compiler.
+	PathAbsolute
+)
+
+// FunctionStyle controls how the function of a frame is rendered.
+type FunctionStyle int
+
+const (
+	// FunctionShort renders the function with its package name, e.g. "users.(*Service).Get".
+	FunctionShort FunctionStyle = iota
+
+	// FunctionQualified renders the function with its package import path,
+	// e.g. "github.com/acme/app/internal/users.(*Service).Get".
+	FunctionQualified
+)
+
+// FrameOrder controls the order of the frames in a rendered trace.
+type FrameOrder int
+
+const (
+	// NewestFirst renders the most recent call first.
+	NewestFirst FrameOrder = iota
+
+	// OldestFirst renders the call closest to the origin of the error first.
+	OldestFirst
+)
+
+// DefaultTraceSeparator is the separator used when TraceRenderer.Separator is empty.
+const DefaultTraceSeparator = " ➡️ "
+
+// TraceRenderer renders traces. The zero value renders traces in the default format:
+//
+//	>>> service >>> [service.go:12] users.(*Service).Get ➡️ [repo.go:40] users.(*Repo).Find
+//
+// The renderer used by ErrorX.Trace can be changed with SetTraceRenderer.
+// Traces sent to peers by the encoders are always rendered in the default format,
+// so that peers can parse them.
+type TraceRenderer struct {
+	// Separator separates the frames. If empty, DefaultTraceSeparator is used.
+	Separator string
+
+	// Paths controls how source files are rendered.
+	Paths PathStyle
+
+	// Functions controls how function names are rendered.
+	Functions FunctionStyle
+
+	// Order controls the order of the frames.
+	Order FrameOrder
+
+	// Module is the module path that PathRelative paths are relative to.
+	// If empty, the main module of the running binary is used.
+	Module string
+
+	// Filter holds the rules applied to the frames before rendering.
+	// If nil, ErrorX.Trace applies the rules of the tracing configuration (see TraceConfig.Filter),
+	// and the other methods apply none.
+	Filter *TraceFilter
+}
+
+// traceRenderer holds the renderer used by ErrorX.Trace.
+var traceRenderer atomic.Pointer[TraceRenderer]
+
+// SetTraceRenderer sets the renderer used by ErrorX.Trace.
+//
+// For example, to avoid the emoji separator and ambiguous base names in logs:
+//
+//	errx.SetTraceRenderer(errx.TraceRenderer{Separator: " <- ", Paths: errx.PathRelative})
+func SetTraceRenderer(r TraceRenderer) {
+	traceRenderer.Store(&r)
+}
+
+// currentTraceRenderer returns the renderer set with SetTraceRenderer, or the default one.
+func currentTraceRenderer() TraceRenderer {
+	if r := traceRenderer.Load(); r != nil {
+		return *r
+	}
+	return TraceRenderer{}
+}
+
+// Render renders the trace of the first ErrorX in the error's chain.
+// If there is none, an empty string is returned.
+func (r TraceRenderer) Render(err error) string {
+	e, ok := Find[*errorX](err)
+	if !ok {
+		return ""
+	}
+	return r.render(resolveFrames(e.callers), e.rawTrace)
+}
+
+// RenderFrames renders frames, given the most recent first, as a trace.
+func (r TraceRenderer) RenderFrames(frames []Frame) string {
+	return r.render(frames, "")
+}
+
+// RenderFrame renders a single frame, e.g. "[service.go:12] users.(*Service).Get".
+// Collapsed frames are followed by their count, e.g. "[tree.go:40] tree.walk (x12)",
+// and placeholder frames are rendered as "... 7 frames omitted".
+func (r TraceRenderer) RenderFrame(f Frame) string {
+	if f.Omitted > 0 {
+		return fmt.Sprintf("... %d frames omitted", f.Omitted)
+	}
+	s := fmt.Sprintf("[%s:%d] %s", r.path(f), f.Line, r.function(f))
+	if f.Repeat > 1 {
+		s += fmt.Sprintf(" (x%d)", f.Repeat)
+	}
+	return s
+}
+
+// render renders the frames, given the most recent first, followed by the trace
+// received as a string from a peer that did not send structured frames.
+//
+// Each run of frames recorded in another service is preceded by a ">>> service >>> " marker.
+func (r TraceRenderer) render(frames []Frame, rawTrace string) string {
+	if r.Filter != nil {
+		frames = r.Filter.Apply(frames)
+	}
+
+	sep := r.Separator
+	if sep == "" {
+		sep = DefaultTraceSeparator
+	}
+
+	parts := make([]string, 0, len(frames)+1)
+	for i, f := range frames {
+		if r.Order == OldestFirst {
+			f = frames[len(frames)-1-i]
+		}
+
+		s := r.RenderFrame(f)
+		if f.Service != "" && (len(parts) == 0 || frames[prevIndex(r.Order, i, len(frames))].Service != f.Service) {
+			s = fmt.Sprintf(">>> %s >>> %s", f.Service, s)
+		}
+		parts = append(parts, s)
+	}
+
+	if rawTrace != "" {
+		if r.Order == OldestFirst {
+			parts = append([]string{rawTrace}, parts...)
+		} else {
+			parts = append(parts, rawTrace)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// prevIndex returns the index in frames of the frame rendered before the i-th rendered frame.
+func prevIndex(order FrameOrder, i, n int) int {
+	if order == OldestFirst {
+		return n - i
+	}
+	return i - 1
+}
+
+// path renders the source file of the frame.
+func (r TraceRenderer) path(f Frame) string {
+	switch r.Paths {
+	case PathAbsolute:
+		if f.Path != "" {
+			return f.Path
+		}
+	case PathRelative:
+		// External test packages live in the directory of the package they test.
+		pkg := strings.TrimSuffix(f.Package, "_test")
+		if pkg == "" || pkg == "main" {
+			break
+		}
+		module := r.Module
+		if module == "" {
+			module = mainModule()
+		}
+		if module != "" && pkg == module {
+			return f.File
+		}
+		if module != "" && strings.HasPrefix(pkg, module+"/") {
+			return pkg[len(module)+1:] + "/" + f.File
+		}
+		return pkg + "/" + f.File
+	}
+	return f.File
+}
+
+// function renders the function of the frame.
+func (r TraceRenderer) function(f Frame) string {
+	if r.Functions == FunctionQualified {
+		return f.Function
+	}
+	return f.ShortFunction()
+}
+
+// mainModule returns the path of the main module of the running binary.
+var mainModule = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Path
+})
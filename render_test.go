@@ -0,0 +1,85 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestTraceRenderer(t *testing.T) {
+	frames := []errx.Frame{
+		{File: "handler.go", Path: "/src/app/api/handler.go", Line: 10, Function: "github.com/code19m/errx/api.(*Handler).Get", Package: "github.com/code19m/errx/api"},
+		{File: "client.go", Path: "/src/app/client.go", Line: 20, Function: "github.com/code19m/errx.call", Package: "github.com/code19m/errx", Service: "users"},
+		{File: "server.go", Path: "/go/pkg/mod/google.golang.org/grpc/server.go", Line: 30, Function: "google.golang.org/grpc.(*Server).handle", Package: "google.golang.org/grpc", Service: "users"},
+	}
+
+	tests := []struct {
+		name     string
+		renderer errx.TraceRenderer
+		want     string
+	}{
+		{
+			name:     "default",
+			renderer: errx.TraceRenderer{},
+			want:     "[handler.go:10] api.(*Handler).Get ➡️ >>> users >>> [client.go:20] errx.call ➡️ [server.go:30] grpc.(*Server).handle",
+		},
+		{
+			name:     "separator and relative paths",
+			renderer: errx.TraceRenderer{Separator: " <- ", Paths: errx.PathRelative},
+			want:     "[api/handler.go:10] api.(*Handler).Get <- >>> users >>> [client.go:20] errx.call <- [google.golang.org/grpc/server.go:30] grpc.(*Server).handle",
+		},
+		{
+			name:     "explicit module",
+			renderer: errx.TraceRenderer{Paths: errx.PathRelative, Module: "github.com/code19m", Separator: " | "},
+			want:     "[errx/api/handler.go:10] api.(*Handler).Get | >>> users >>> [errx/client.go:20] errx.call | [google.golang.org/grpc/server.go:30] grpc.(*Server).handle",
+		},
+		{
+			name:     "absolute paths and qualified functions",
+			renderer: errx.TraceRenderer{Paths: errx.PathAbsolute, Functions: errx.FunctionQualified, Separator: " ; "},
+			want:     "[/src/app/api/handler.go:10] github.com/code19m/errx/api.(*Handler).Get ; >>> users >>> [/src/app/client.go:20] github.com/code19m/errx.call ; [/go/pkg/mod/google.golang.org/grpc/server.go:30] google.golang.org/grpc.(*Server).handle",
+		},
+		{
+			name:     "oldest first",
+			renderer: errx.TraceRenderer{Order: errx.OldestFirst, Separator: " -> "},
+			want:     ">>> users >>> [server.go:30] grpc.(*Server).handle -> [client.go:20] errx.call -> [handler.go:10] api.(*Handler).Get",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.renderer.RenderFrames(frames); got != tt.want {
+				t.Errorf("expected\n%q\ngot\n%q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetTraceRenderer(t *testing.T) {
+	errx.SetTraceRenderer(errx.TraceRenderer{Separator: " <- ", Paths: errx.PathRelative})
+	defer errx.SetTraceRenderer(errx.TraceRenderer{})
+
+	err := generateErrorThroughChain()
+	trace := err.(errx.ErrorX).Trace()
+	if strings.Contains(trace, "➡️") || !strings.Contains(trace, "[trace_test.go:") || strings.Count(trace, " <- ") != 2 {
+		t.Errorf("unexpected trace: %v", trace)
+	}
+
+	t.Run("per renderer", func(t *testing.T) {
+		got := errx.TraceRenderer{Functions: errx.FunctionQualified}.Render(err)
+		if !strings.Contains(got, "github.com/code19m/errx_test.innerErrorGenerator") || !strings.Contains(got, " ➡️ ") {
+			t.Errorf("unexpected trace: %v", got)
+		}
+	})
+
+	t.Run("encoders use the default format", func(t *testing.T) {
+		data, _ := json.Marshal(err)
+		var body struct {
+			Trace string `json:"trace"`
+		}
+		_ = json.Unmarshal(data, &body)
+		if !strings.Contains(body.Trace, " ➡️ ") {
+			t.Errorf("expected default format, got: %v", body.Trace)
+		}
+	})
+}
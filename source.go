@@ -0,0 +1,307 @@
+package errx
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultSourceContext is the number of lines shown before and after the line of a frame
+// when SourceRenderer.Context is zero.
+const DefaultSourceContext = 3
+
+// Link formats for SourceRenderer.Links that open the frame in a local editor.
+const (
+	LinkVSCode  = "vscode://file/{path}:{line}"
+	LinkGoLand  = "goland://open?file={path}&line={line}"
+	LinkSublime = "subl://open?url=file://{path}&line={line}"
+)
+
+// SourceRenderer renders the frames of an error with the lines of code around each call site,
+// read from the local source files. It is intended for development only:
+// the source files must be present where the binary runs, at the paths recorded by the compiler.
+//
+// Frames whose source file cannot be read, such as frames recorded in other services
+// or in binaries built with -trimpath, are rendered without code.
+//
+// Use SetSourceContext to include the code in the %+v dump of errors,
+// and WriteHTTPError to serve errors as HTML debug pages.
+type SourceRenderer struct {
+	// Context is the number of lines shown before and after the line of each frame.
+	// If zero, DefaultSourceContext is used.
+	Context int
+
+	// Links is the format of the link written for each frame, with {path} and {line} placeholders,
+	// for example LinkVSCode. If empty, frames are linked as "path:line",
+	// which most editors and terminals can open.
+	Links string
+}
+
+// SourceLine is a line of source code.
+type SourceLine struct {
+	Number int
+	Text   string
+}
+
+// SourceFrame is a frame with the source code around its call site.
+type SourceFrame struct {
+	Frame
+
+	// Link opens the frame in an editor, see SourceRenderer.Links.
+	Link string
+
+	// Lines are the lines around the call site. They are empty if the source file cannot be read.
+	Lines []SourceLine
+}
+
+// sourceRenderer holds the renderer set with SetSourceContext.
+var sourceRenderer atomic.Pointer[SourceRenderer]
+
+// SetSourceContext includes the source code around each call site in the %+v dump of errors,
+// rendered with the given renderer. A nil renderer turns it off, which is the default.
+//
+//	if env == "dev" {
+//	    errx.SetSourceContext(&errx.SourceRenderer{Links: errx.LinkVSCode})
+//	}
+func SetSourceContext(r *SourceRenderer) {
+	if r != nil {
+		c := *r
+		r = &c
+	}
+	sourceRenderer.Store(r)
+}
+
+// Frames returns the frames of the first ErrorX in the error's chain, the most recent first,
+// with the source code around their call sites. If the error has a captured stack (see WithStack),
+// the stack frames are returned instead, as they cover the whole call path.
+func (r SourceRenderer) Frames(err error) []SourceFrame {
+	e, ok := Find[*errorX](err)
+	if !ok {
+		return nil
+	}
+	return r.frames(*e)
+}
+
+// frames returns the source frames of the error.
+func (r SourceRenderer) frames(e errorX) []SourceFrame {
+	frames := e.Frames()
+	if e.stack.Len() > 0 {
+		frames = e.stack.Frames()
+	}
+
+	files := make(map[string][]string)
+	sfs := make([]SourceFrame, len(frames))
+	for i, f := range frames {
+		sfs[i] = SourceFrame{Frame: f, Link: r.link(f), Lines: r.lines(files, f)}
+	}
+	return sfs
+}
+
+// Render writes the frames of the error with the source code around their call sites:
+//
+//	/src/app/users/service.go:42 users.(*Service).Get
+//	     40	func (s *Service) Get(id string) (*User, error) {
+//	     41		if id == "" {
+//	  >  42			return nil, errx.New("empty id")
+//	     43		}
+//	     44
+func (r SourceRenderer) Render(w io.Writer, err error) {
+	e, ok := Find[*errorX](err)
+	if !ok {
+		return
+	}
+	r.render(w, r.frames(*e))
+}
+
+// render writes the source frames.
+func (r SourceRenderer) render(w io.Writer, frames []SourceFrame) {
+	for i, f := range frames {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		if f.Omitted > 0 {
+			fmt.Fprintf(w, "... %d frames omitted\n", f.Omitted)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s\n", f.Link, f.ShortFunction())
+		if len(f.Lines) == 0 {
+			io.WriteString(w, "     (source not available)\n")
+			continue
+		}
+		for _, l := range f.Lines {
+			marker := "   "
+			if l.Number == f.Line {
+				marker = "  >"
+			}
+			fmt.Fprintf(w, "%s%4d\t%s\n", marker, l.Number, l.Text)
+		}
+	}
+}
+
+// link returns the link to the frame's call site.
+func (r SourceRenderer) link(f Frame) string {
+	path := f.Path
+	if path == "" {
+		path = f.File
+	}
+	if r.Links == "" {
+		return fmt.Sprintf("%s:%d", path, f.Line)
+	}
+	return strings.NewReplacer("{path}", path, "{line}", strconv.Itoa(f.Line)).Replace(r.Links)
+}
+
+// lines returns the lines around the frame's call site.
+// Files are read once per render and cached in files, missing files are cached as nil.
+func (r SourceRenderer) lines(files map[string][]string, f Frame) []SourceLine {
+	if f.Path == "" || f.Service != "" || f.Line <= 0 {
+		return nil
+	}
+
+	src, ok := files[f.Path]
+	if !ok {
+		src = readLines(f.Path)
+		files[f.Path] = src
+	}
+	if f.Line > len(src) {
+		return nil
+	}
+
+	n := r.Context
+	if n <= 0 {
+		n = DefaultSourceContext
+	}
+	from, to := max(f.Line-n, 1), min(f.Line+n, len(src))
+
+	lines := make([]SourceLine, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		lines = append(lines, SourceLine{Number: i, Text: src[i-1]})
+	}
+	return lines
+}
+
+// readLines reads the lines of a file. It returns nil if the file cannot be read.
+func readLines(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if sc.Err() != nil {
+		return nil
+	}
+	return lines
+}
+
+// WriteHTTPError writes the error to the HTTP response as an HTML debug page,
+// with its internal message, code, type, fields, details and the source code of its frames.
+// It can be used as an HTTPErrorWriter, for example with RecoverHTTP.
+//
+// The page exposes internal information and must only be served in development.
+func (r SourceRenderer) WriteHTTPError(w http.ResponseWriter, req *http.Request, err error) {
+	if err == nil {
+		return
+	}
+
+	e := errorXOf(err)
+	page := debugPage{
+		Message: e.Error(),
+		Code:    e.Code(),
+		Type:    e.Type().String(),
+		Fields:  e.Fields(),
+		Details: e.Details(),
+		Trace:   e.Trace(),
+		Frames:  r.frames(*e),
+		Links:   r.Links != "",
+	}
+	if req != nil {
+		page.Request = req.Method + " " + req.URL.String()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(HTTPStatus(e.Type()))
+	_ = debugPageTemplate.Execute(w, page)
+}
+
+// debugPage is the data of the HTML debug page.
+type debugPage struct {
+	Message string
+	Code    string
+	Type    string
+	Request string
+	Fields  M
+	Details D
+	Trace   string
+	Frames  []SourceFrame
+	Links   bool
+}
+
+var debugPageTemplate = template.Must(template.New("debug").Funcs(template.FuncMap{
+	// Editor links use custom schemes, which html/template would otherwise reject as unsafe.
+	"link": func(s string) template.URL { return template.URL(s) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Type}}: {{.Code}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+pre { background: #f6f6f6; padding: .5em; overflow-x: auto; }
+.frame { margin-bottom: 1.5em; }
+.current { background: #ffe3e3; font-weight: bold; }
+.missing { color: #888; font-style: italic; }
+table { border-collapse: collapse; }
+td { padding: .2em 1em .2em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{.Message}}</h1>
+<table>
+<tr><td>Code</td><td>{{.Code}}</td></tr>
+<tr><td>Type</td><td>{{.Type}}</td></tr>
+{{- if .Request}}
+<tr><td>Request</td><td>{{.Request}}</td></tr>
+{{- end}}
+{{- range $k, $v := .Fields}}
+<tr><td>Field {{$k}}</td><td>{{$v}}</td></tr>
+{{- end}}
+{{- range $k, $v := .Details}}
+<tr><td>Detail {{$k}}</td><td><pre>{{$v}}</pre></td></tr>
+{{- end}}
+</table>
+{{- if .Trace}}
+<h2>Trace</h2>
+<pre>{{.Trace}}</pre>
+{{- end}}
+<h2>Frames</h2>
+{{- range .Frames}}
+<div class="frame">
+{{- if .Omitted}}
+<div class="missing">{{.Omitted}} frames omitted</div>
+{{- else}}
+{{if $.Links}}<a href="{{link .Link}}">{{.Link}}</a>{{else}}<code>{{.Link}}</code>{{end}} {{.ShortFunction}}
+{{- if .Lines}}
+<pre>{{$line := .Line}}{{range .Lines}}<span{{if eq .Number $line}} class="current"{{end}}>{{printf "%4d" .Number}}  {{.Text}}</span>
+{{end}}</pre>
+{{- else}}
+<div class="missing">source not available</div>
+{{- end}}
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,121 @@
+package errx_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func sourceHelper() error {
+	return errx.New("source error") // sourceHelperLine
+}
+
+func TestSourceRenderer(t *testing.T) {
+	t.Run("render source around call sites", func(t *testing.T) {
+		err := sourceHelper()
+		sourceHelperLine := errx.GetFrames(err)[0].Line
+
+		var b bytes.Buffer
+		errx.SourceRenderer{Context: 1}.Render(&b, err)
+		lines := strings.Split(b.String(), "\n")
+
+		if !strings.HasSuffix(lines[0], fmt.Sprintf("source_test.go:%d errx_test.sourceHelper", sourceHelperLine)) || !strings.HasPrefix(lines[0], "/") {
+			t.Errorf("unexpected header: %q", lines[0])
+		}
+		want := fmt.Sprintf("  >%4d\t\treturn errx.New(\"source error\") // sourceHelperLine", sourceHelperLine)
+		if len(lines) < 4 || lines[2] != want {
+			t.Errorf("expected\n%q\ngot\n%q", want, lines)
+		}
+		if !strings.HasPrefix(lines[1], fmt.Sprintf("   %4d\t", sourceHelperLine-1)) || !strings.HasPrefix(lines[3], fmt.Sprintf("   %4d\t", sourceHelperLine+1)) {
+			t.Errorf("expected one line of context: %q", lines)
+		}
+	})
+
+	t.Run("editor links", func(t *testing.T) {
+		frames := errx.SourceRenderer{Links: errx.LinkVSCode}.Frames(sourceHelper())
+		want := fmt.Sprintf("vscode://file/%s:%d", frames[0].Path, frames[0].Line)
+		if frames[0].Link != want {
+			t.Errorf("expected %q, got %q", want, frames[0].Link)
+		}
+		if len(frames[0].Lines) != 2*errx.DefaultSourceContext+1 {
+			t.Errorf("expected default context, got %v", frames[0].Lines)
+		}
+	})
+
+	t.Run("missing source files", func(t *testing.T) {
+		data := []byte(`{"code":"X","message":"remote","type":"T_Internal","frames":[{"file":"gone.go","path":"/nonexistent/gone.go","line":3,"function":"gone.f"},{"file":"a.go","line":1,"function":"a.f","service":"users"}]}`)
+		_, err := errx.FromJSON(data)
+
+		frames := errx.SourceRenderer{}.Frames(err)
+		if len(frames) != 3 {
+			t.Fatalf("expected 3 frames, got %v", frames)
+		}
+		if len(frames[1].Lines) != 0 || len(frames[2].Lines) != 0 {
+			t.Errorf("expected no source for missing files, got %v", frames)
+		}
+
+		var b bytes.Buffer
+		errx.SourceRenderer{}.Render(&b, err)
+		if !strings.Contains(b.String(), "/nonexistent/gone.go:3 gone.f\n     (source not available)\n") {
+			t.Errorf("unexpected render: %v", b.String())
+		}
+	})
+
+	t.Run("not an ErrorX", func(t *testing.T) {
+		if frames := (errx.SourceRenderer{}).Frames(fmt.Errorf("plain")); frames != nil {
+			t.Errorf("expected no frames, got %v", frames)
+		}
+	})
+}
+
+func TestSetSourceContext(t *testing.T) {
+	errx.SetSourceContext(&errx.SourceRenderer{Context: 1})
+	defer errx.SetSourceContext(nil)
+
+	dump := fmt.Sprintf("%+v", sourceHelper())
+	if !strings.Contains(dump, "\n\nsource:\n") || !strings.Contains(dump, `return errx.New("source error") // sourceHelperLine`) {
+		t.Errorf("expected source in dump: %v", dump)
+	}
+
+	errx.SetSourceContext(nil)
+	if dump := fmt.Sprintf("%+v", sourceHelper()); strings.Contains(dump, "source:") {
+		t.Errorf("expected no source in dump: %v", dump)
+	}
+}
+
+func TestSourceRendererWriteHTTPError(t *testing.T) {
+	err := errx.Wrap(sourceHelper(), errx.WithFields(errx.M{"name": "<script>"}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	errx.SourceRenderer{Links: errx.LinkVSCode}.WriteHTTPError(rec, req, err)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected status: %v", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type: %v", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"<h1>source error</h1>",
+		"GET /users/1",
+		`<a href="vscode://file/`,
+		`class="current">`,
+		"&lt;script&gt;",
+		"return errx.New(&#34;source error&#34;)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in page:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "<script>") {
+		t.Errorf("expected fields to be escaped")
+	}
+}
@@ -0,0 +1,24 @@
+package errx
+
+// WithSpanIDs stores the IDs of the tracing span the error occurred in, in hex,
+// so that logged errors can be looked up in the tracing backend.
+// If the IDs are stored several times, as the error goes up nested spans, the most recent ones are kept.
+//
+// The IDs are carried over gRPC and JSON, but not written to clients by WriteHTTPError.
+// The errxotel package stores the IDs of OpenTelemetry spans.
+func WithSpanIDs(traceID, spanID string) OptionFunc {
+	return func(e *errorX) {
+		e.traceID = traceID
+		e.spanID = spanID
+	}
+}
+
+// GetSpanIDs returns the span IDs stored with WithSpanIDs in the first ErrorX in the error's chain.
+// The boolean result reports whether the error carries them.
+func GetSpanIDs(err error) (traceID, spanID string, ok bool) {
+	e, found := Find[*errorX](err)
+	if !found || e.traceID == "" {
+		return "", "", false
+	}
+	return e.traceID, e.spanID, true
+}
@@ -0,0 +1,73 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestSpanIDs(t *testing.T) {
+	t.Run("store span IDs", func(t *testing.T) {
+		err := errx.New("error", errx.WithSpanIDs(testTraceID, testSpanID), errx.WithTracePrefix("users"))
+
+		traceID, spanID, ok := errx.GetSpanIDs(fmt.Errorf("wrapped: %w", err))
+		if !ok || traceID != testTraceID || spanID != testSpanID {
+			t.Errorf("unexpected span IDs: %v %v %v", traceID, spanID, ok)
+		}
+		if !strings.Contains(fmt.Sprintf("%+v", err), "trace_id: "+testTraceID) {
+			t.Errorf("expected span IDs in the dump, got %+v", err)
+		}
+	})
+
+	t.Run("most recent span wins", func(t *testing.T) {
+		err := errx.Wrap(errx.New("error", errx.WithSpanIDs(testTraceID, "1111111111111111")), errx.WithSpanIDs(testTraceID, testSpanID))
+		if _, spanID, _ := errx.GetSpanIDs(err); spanID != testSpanID {
+			t.Errorf("expected the most recent span, got %v", spanID)
+		}
+	})
+
+	t.Run("no span IDs", func(t *testing.T) {
+		if _, _, ok := errx.GetSpanIDs(errx.New("error")); ok {
+			t.Errorf("expected no span IDs")
+		}
+		if _, _, ok := errx.GetSpanIDs(fmt.Errorf("standard")); ok {
+			t.Errorf("expected no span IDs for standard errors")
+		}
+	})
+
+	t.Run("span IDs are sent to peers", func(t *testing.T) {
+		err := errx.New("error", errx.WithSpanIDs(testTraceID, testSpanID))
+
+		_, received := errx.FromGRPCError(errx.ToGRPCError(err))
+		if traceID, spanID, ok := errx.GetSpanIDs(received); !ok || traceID != testTraceID || spanID != testSpanID {
+			t.Errorf("expected span IDs to survive gRPC, got %v %v %v", traceID, spanID, ok)
+		}
+
+		data, jerr := json.Marshal(err)
+		if jerr != nil {
+			t.Fatalf("unexpected error: %v", jerr)
+		}
+		_, received = errx.FromJSON(data)
+		if traceID, spanID, ok := errx.GetSpanIDs(received); !ok || traceID != testTraceID || spanID != testSpanID {
+			t.Errorf("expected span IDs to survive JSON, got %v %v %v", traceID, spanID, ok)
+		}
+	})
+
+	t.Run("span IDs are not written to clients", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		errx.WriteHTTPError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errx.New("error", errx.WithSpanIDs(testTraceID, testSpanID)))
+		if strings.Contains(rec.Body.String(), testTraceID) {
+			t.Errorf("expected no span IDs in the response, got %s", rec.Body.String())
+		}
+	})
+}
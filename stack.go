@@ -0,0 +1,201 @@
+package errx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+)
+
+// maxStackDepth is the maximum number of frames captured in a stack.
+const maxStackDepth = 64
+
+// Stack is the full call stack of the goroutine that created an error.
+// It is captured only when enabled with WithStack, EnableStackCapture or the TraceStack mode.
+type Stack struct {
+	goroutine uint64
+	pcs       []uintptr
+}
+
+// Len returns the number of frames in the stack.
+func (s Stack) Len() int {
+	return len(s.pcs)
+}
+
+// Frames returns the frames of the stack, the innermost call first.
+func (s Stack) Frames() []Frame {
+	if len(s.pcs) == 0 {
+		return nil
+	}
+
+	frames := make([]Frame, 0, len(s.pcs))
+	it := runtime.CallersFrames(s.pcs)
+	for {
+		f, more := it.Next()
+		frames = append(frames, newFrame(f.File, f.Line, f.Function))
+		if !more {
+			return frames
+		}
+	}
+}
+
+// String renders the stack like the goroutine trace of a Go panic,
+// so that it can be read by the usual tools:
+//
+//	goroutine 7 [running]:
+//	github.com/acme/app/users.(*Service).Get(...)
+//		/src/app/users/service.go:42 +0x1d
+//
+// An empty stack is rendered as an empty string.
+func (s Stack) String() string {
+	if len(s.pcs) == 0 {
+		return ""
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "goroutine %d [running]:\n", s.goroutine)
+
+	it := runtime.CallersFrames(s.pcs)
+	for {
+		f, more := it.Next()
+		fmt.Fprintf(&b, "%s(...)\n\t%s:%d", f.Function, f.File, f.Line)
+		if f.Entry != 0 {
+			fmt.Fprintf(&b, " +0x%x", f.PC-f.Entry)
+		}
+		b.WriteByte('\n')
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// GetStack returns the call stack captured when the first ErrorX in the error's chain was created.
+// It is empty unless the capture was enabled with WithStack or EnableStackCapture.
+func GetStack(err error) Stack {
+	if e, ok := Find[*errorX](err); ok {
+		return e.stack
+	}
+	return Stack{}
+}
+
+// EnableStackCapture enables capturing the full call stack when errors are created
+// with New, Newf or Wrap, for the given types only, or for all types if none are given.
+//
+// Capturing a stack costs considerably more than recording a trace frame,
+// so it is intended to be enabled for the types where it helps, such as T_Internal:
+//
+//	errx.EnableStackCapture(errx.T_Internal)
+//
+// It is a shorthand for setting the TraceStack mode in the tracing configuration (see SetTraceConfig).
+func EnableStackCapture(types ...Type) {
+	updateTraceConfig(func(c *TraceConfig) {
+		if len(types) == 0 {
+			c.Mode = TraceStack
+			return
+		}
+		if c.Types == nil {
+			c.Types = make(map[Type]TraceMode, len(types))
+		}
+		for _, t := range types {
+			c.Types[t] = TraceStack
+		}
+	})
+}
+
+// DisableStackCapture disables the stack capture enabled with EnableStackCapture,
+// by replacing the TraceStack mode with TraceFrame in the tracing configuration.
+// Stacks requested with WithStack are still captured.
+func DisableStackCapture() {
+	updateTraceConfig(func(c *TraceConfig) {
+		if c.Mode == TraceStack {
+			c.Mode = TraceFrame
+		}
+		for t, mode := range c.Types {
+			if mode == TraceStack {
+				c.Types[t] = TraceFrame
+			}
+		}
+	})
+}
+
+// WithStack captures the full call stack of the error, regardless of the global setting
+// (see EnableStackCapture). Errors that already have a stack keep it,
+// so wrapping never replaces the stack captured closer to the origin of the error.
+func WithStack() OptionFunc {
+	return func(e *errorX) {
+		e.stackWanted = true
+	}
+}
+
+// captureStack captures the call stack if it is requested for the error and not captured yet.
+// The skip parameter has the same meaning as for addTrace.
+func (e *errorX) captureStack(skip int) {
+	if e.stack.pcs != nil {
+		return
+	}
+	if !e.stackWanted {
+		c := currentTraceConfig()
+		if c.modeFor(e.type_) != TraceStack || !c.sampleStack() {
+			return
+		}
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	e.stack = Stack{goroutine: goroutineID(), pcs: pcs[:n:n]}
+}
+
+// goroutineID returns the ID of the current goroutine,
+// parsed from the header of its stack trace ("goroutine 7 [running]:").
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// Format implements the fmt.Formatter interface.
+//
+// The %s and %v verbs print the message, %q prints it quoted.
+// The %+v verb prints a detailed dump of the error: the message, code, type and trace,
+// followed by the source code around each call site if enabled with SetSourceContext,
+// and the stack in the format of a Go panic, if one was captured.
+func (e errorX) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.writeDump(s)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(errx.ErrorX=%s)", verb, e.Error())
+	}
+}
+
+// writeDump writes the detailed dump of the error printed by the %+v verb.
+func (e errorX) writeDump(w io.Writer) {
+	fmt.Fprintf(w, "%s\ncode: %s, type: %s", e.Error(), e.code, e.type_)
+	if trace := e.Trace(); trace != "" {
+		fmt.Fprintf(w, "\ntrace: %s", trace)
+	}
+	if e.traceID != "" {
+		fmt.Fprintf(w, "\ntrace_id: %s, span_id: %s", e.traceID, e.spanID)
+	}
+	if r := sourceRenderer.Load(); r != nil {
+		io.WriteString(w, "\n\nsource:\n")
+		r.render(w, r.frames(e))
+	}
+	if e.stack.Len() > 0 {
+		fmt.Fprintf(w, "\n\n%s", e.stack)
+	}
+}
@@ -0,0 +1,101 @@
+package errx_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestWithStack(t *testing.T) {
+	t.Run("no stack by default", func(t *testing.T) {
+		err := errx.New("error")
+		if errx.GetStack(err).Len() != 0 {
+			t.Errorf("expected no stack")
+		}
+	})
+
+	t.Run("capture stack on creation", func(t *testing.T) {
+		err := stackHelper()
+		stack := errx.GetStack(err)
+
+		frames := stack.Frames()
+		if len(frames) < 2 {
+			t.Fatalf("expected several frames, got %v", frames)
+		}
+		if frames[0].ShortFunction() != "errx_test.stackHelper" {
+			t.Errorf("expected innermost frame first, got %v", frames[0])
+		}
+		if frames[1].ShortFunction() != "errx_test.TestWithStack.func2" {
+			t.Errorf("expected caller frame, got %v", frames[1])
+		}
+	})
+
+	t.Run("wrapping keeps the original stack", func(t *testing.T) {
+		err := stackHelper()
+		wrapped := errx.Wrap(err, errx.WithStack())
+		if errx.GetStack(wrapped).Frames()[0].ShortFunction() != "errx_test.stackHelper" {
+			t.Errorf("expected original stack to be kept")
+		}
+	})
+
+	t.Run("render like a panic", func(t *testing.T) {
+		s := errx.GetStack(stackHelper()).String()
+		lines := strings.Split(s, "\n")
+		if !strings.HasPrefix(lines[0], "goroutine ") || !strings.HasSuffix(lines[0], " [running]:") || lines[0] == "goroutine 0 [running]:" {
+			t.Errorf("unexpected header: %q", lines[0])
+		}
+		if lines[1] != "github.com/code19m/errx_test.stackHelper(...)" {
+			t.Errorf("unexpected function line: %q", lines[1])
+		}
+		if !strings.HasPrefix(lines[2], "\t") || !strings.Contains(lines[2], "stack_test.go:") || !strings.Contains(lines[2], " +0x") {
+			t.Errorf("unexpected location line: %q", lines[2])
+		}
+	})
+}
+
+func TestEnableStackCapture(t *testing.T) {
+	errx.EnableStackCapture(errx.T_Internal)
+	defer errx.DisableStackCapture()
+
+	if errx.GetStack(errx.New("internal")).Len() == 0 {
+		t.Errorf("expected stack for T_Internal")
+	}
+	if errx.GetStack(errx.New("missing", errx.WithType(errx.T_NotFound))).Len() != 0 {
+		t.Errorf("expected no stack for T_NotFound")
+	}
+	if errx.GetStack(errx.Newf("formatted %d", 1)).Len() == 0 {
+		t.Errorf("expected stack for Newf")
+	}
+
+	errx.EnableStackCapture()
+	if errx.GetStack(errx.New("missing", errx.WithType(errx.T_NotFound))).Len() == 0 {
+		t.Errorf("expected stack for all types")
+	}
+
+	errx.DisableStackCapture()
+	if errx.GetStack(errx.New("internal")).Len() != 0 {
+		t.Errorf("expected no stack after disabling")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := errx.New("boom", errx.WithCode("BOOM"), errx.WithStack())
+
+	if got := fmt.Sprintf("%v|%s|%q", err, err, err); got != `boom|boom|"boom"` {
+		t.Errorf("unexpected formatting: %v", got)
+	}
+
+	dump := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(dump, "boom\ncode: BOOM, type: T_Internal\ntrace: [stack_test.go:") {
+		t.Errorf("unexpected dump: %v", dump)
+	}
+	if !strings.Contains(dump, "\n\ngoroutine ") || !strings.Contains(dump, "errx_test.TestFormat(...)") {
+		t.Errorf("expected stack in dump: %v", dump)
+	}
+}
+
+func stackHelper() error {
+	return errx.New("error", errx.WithStack())
+}
@@ -0,0 +1,79 @@
+package errx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// P is a shorthand for a map of named message template parameters.
+type P map[string]any
+
+// ParamDetailPrefix is the prefix of the detail keys holding template parameters.
+// For example, the parameter "user_id" is recorded in the details as "param.user_id".
+const ParamDetailPrefix = "param."
+
+// renderTemplate replaces the "{name}" placeholders of the template with the
+// matching parameters. Placeholders without a parameter are left unchanged.
+func renderTemplate(template string, params P) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	var b strings.Builder
+	b.Grow(len(template))
+
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		name := template[start+1 : end]
+		if v, ok := params[name]; ok {
+			b.WriteString(template[:start])
+			b.WriteString(fmt.Sprint(v))
+		} else {
+			b.WriteString(template[:end+1])
+		}
+		template = template[end+1:]
+	}
+
+	b.WriteString(template)
+	return b.String()
+}
+
+// stringParams converts parameters to strings, as carried by the gRPC proto message.
+func stringParams(params P) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(params))
+	for k, v := range params {
+		result[k] = fmt.Sprint(v)
+	}
+	return result
+}
+
+// GetTemplate returns the message template of the first ErrorX in the error's chain,
+// if its message was built from one.
+func GetTemplate(err error) string {
+	if e, ok := Find[*errorX](err); ok {
+		return e.template
+	}
+	return ""
+}
+
+// GetParams returns the named parameters of the message template of the first ErrorX in the error's chain.
+// Clients can use them together with the code to re-render the message, e.g. in another language.
+func GetParams(err error) P {
+	if e, ok := Find[*errorX](err); ok {
+		return e.params
+	}
+	return nil
+}
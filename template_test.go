@@ -0,0 +1,101 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func init() {
+	errx.Declare(errx.CodeDef{Code: "TEMPLATE_QUOTA", Type: errx.T_Throttling, Message: "quota of {limit} requests exceeded"})
+}
+
+func TestWithTemplate(t *testing.T) {
+	t.Run("render template lazily", func(t *testing.T) {
+		err := errx.New("", errx.WithTemplate("user {user_id} not found in {org}", errx.P{"user_id": 42, "org": "acme"}))
+		e := err.(errx.ErrorX)
+
+		if e.Error() != "user 42 not found in acme" {
+			t.Errorf("unexpected message: %v", e.Error())
+		}
+		if errx.GetTemplate(e) != "user {user_id} not found in {org}" {
+			t.Errorf("unexpected template: %v", errx.GetTemplate(e))
+		}
+		if errx.GetParams(e)["user_id"] != 42 {
+			t.Errorf("unexpected params: %v", errx.GetParams(e))
+		}
+		if e.Details()["param.user_id"] != 42 {
+			t.Errorf("expected params in details, got: %v", e.Details())
+		}
+	})
+
+	t.Run("missing parameters are left as placeholders", func(t *testing.T) {
+		err := errx.New("", errx.WithTemplate("{a} and {b} and {", errx.P{"a": 1}))
+		if err.Error() != "1 and {b} and {" {
+			t.Errorf("unexpected message: %v", err.Error())
+		}
+	})
+
+	t.Run("wrapping keeps template and params", func(t *testing.T) {
+		err := errx.New("", errx.WithTemplate("order {id} is closed", errx.P{"id": 7}))
+		err = errx.Wrap(err, errx.WithParams(errx.P{"id": 8}))
+		if err.Error() != "order 8 is closed" {
+			t.Errorf("unexpected message: %v", err.Error())
+		}
+	})
+
+	t.Run("catalog message as template", func(t *testing.T) {
+		err := errx.New("", errx.WithCode("TEMPLATE_QUOTA"), errx.WithParams(errx.P{"limit": 100}))
+		if err.Error() != "quota of 100 requests exceeded" {
+			t.Errorf("unexpected message: %v", err.Error())
+		}
+	})
+}
+
+func TestTemplateEncoding(t *testing.T) {
+	src := errx.New("", errx.WithCode("USER_NOT_FOUND"), errx.WithTemplate("user {user_id} not found", errx.P{"user_id": 42}))
+
+	// The internal template is replaced with the generic public message, while the parameters
+	// are sent, so that clients can re-render the message from the code and parameters.
+	t.Run("gRPC", func(t *testing.T) {
+		_, err := errx.FromGRPCError(errx.ToGRPCError(src))
+		if errx.GetTemplate(err) != "" || errx.GetParams(err)["user_id"] != "42" {
+			t.Errorf("unexpected template or params: %v, %v", errx.GetTemplate(err), errx.GetParams(err))
+		}
+		if err.Error() != "Internal server error" {
+			t.Errorf("unexpected message: %v", err.Error())
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, _ := json.Marshal(src)
+		_, err := errx.FromJSON(data)
+		if errx.GetTemplate(err) != "" || errx.GetParams(err)["user_id"] != float64(42) {
+			t.Errorf("unexpected template or params: %v, %v", errx.GetTemplate(err), errx.GetParams(err))
+		}
+	})
+
+	t.Run("GraphQL", func(t *testing.T) {
+		ext := errx.ToGraphQLErrors(src)[0].Extensions
+		if _, ok := ext["template"]; ok || ext["params"].(errx.P)["user_id"] != 42 {
+			t.Errorf("unexpected extensions: %v", ext)
+		}
+	})
+
+	t.Run("Twirp", func(t *testing.T) {
+		_, err := errx.FromTwirpError(errx.ToTwirpError(src))
+		if errx.GetTemplate(err) != "" || errx.GetParams(err)["user_id"] != "42" {
+			t.Errorf("unexpected template or params: %v, %v", errx.GetTemplate(err), errx.GetParams(err))
+		}
+	})
+
+	t.Run("public template", func(t *testing.T) {
+		err := errx.Wrap(src, errx.WithPublicMessage("user {user_id} does not exist"))
+
+		_, received := errx.FromGRPCError(errx.ToGRPCError(err))
+		if errx.GetTemplate(received) != "user {user_id} does not exist" || received.Error() != "user 42 does not exist" {
+			t.Errorf("unexpected template or message: %v, %v", errx.GetTemplate(received), received)
+		}
+	})
+}
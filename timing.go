@@ -0,0 +1,76 @@
+package errx
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// HopKind is the kind of a step an error went through after its creation.
+type HopKind string
+
+const (
+	// HopWrap is recorded by Wrap and the other wrapping functions.
+	HopWrap HopKind = "wrap"
+
+	// HopSend is recorded when the error is converted to be sent to a peer, by ToGRPCError.
+	HopSend HopKind = "send"
+
+	// HopReceive is recorded when an error sent by a peer is decoded, by FromGRPCError, FromJSON
+	// and the other decoders.
+	HopReceive HopKind = "receive"
+)
+
+// Hop is a step an error went through after its creation, with the time it happened.
+//
+// Hops are carried over gRPC and JSON together with the creation time, so the time an error
+// spent moving up the layers and across services can be measured where it is logged.
+// Times recorded in different services are only as comparable as the clocks of their hosts.
+type Hop struct {
+	Kind HopKind   `json:"kind"`
+	Time time.Time `json:"time"`
+}
+
+// clock holds the function set with SetClock.
+var clock atomic.Pointer[func() time.Time]
+
+// SetClock sets the function returning the current time, used to timestamp errors.
+// It is intended for tests. A nil function restores time.Now, which is the default.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&now)
+}
+
+// clockNow returns the current time from the clock set with SetClock.
+func clockNow() time.Time {
+	if now := clock.Load(); now != nil {
+		return (*now)()
+	}
+	return time.Now()
+}
+
+// addHop records a step of the error at the current time.
+func (e *errorX) addHop(kind HopKind) {
+	e.hops = append(e.hops, Hop{Kind: kind, Time: clockNow()})
+}
+
+// GetCreated returns the creation time of the first ErrorX in the error's chain.
+// The boolean result reports whether the error carries a creation time.
+func GetCreated(err error) (time.Time, bool) {
+	e, ok := Find[*errorX](err)
+	if !ok || e.created.IsZero() {
+		return time.Time{}, false
+	}
+	return e.created, true
+}
+
+// GetHops returns the steps the first ErrorX in the error's chain went through after its creation,
+// the oldest first: wraps, and the sending and receiving across services.
+func GetHops(err error) []Hop {
+	if e, ok := Find[*errorX](err); ok {
+		return e.Hops()
+	}
+	return nil
+}
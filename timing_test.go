@@ -0,0 +1,132 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/code19m/errx"
+)
+
+// tickingClock returns a clock that advances by one second on each call, starting at start.
+func tickingClock(start time.Time) func() time.Time {
+	next := start
+	return func() time.Time {
+		now := next
+		next = next.Add(time.Second)
+		return now
+	}
+}
+
+func assertHops(t *testing.T, got []errx.Hop, start time.Time, kinds ...errx.HopKind) {
+	t.Helper()
+	if len(got) != len(kinds) {
+		t.Fatalf("expected hops %v, got %v", kinds, got)
+	}
+	for i, h := range got {
+		want := start.Add(time.Duration(i+1) * time.Second)
+		if h.Kind != kinds[i] || !h.Time.Equal(want) {
+			t.Errorf("expected hop %d to be %s at %v, got %s at %v", i, kinds[i], want, h.Kind, h.Time)
+		}
+	}
+}
+
+func TestTiming(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	errx.SetClock(tickingClock(start))
+	defer errx.SetClock(nil)
+
+	t.Run("creation and wraps", func(t *testing.T) {
+		errx.SetClock(tickingClock(start))
+
+		err := errx.New("error")
+		wrapped := errx.Wrap(errx.Wrap(err))
+
+		if created, ok := errx.GetCreated(wrapped); !ok || !created.Equal(start) {
+			t.Errorf("expected creation time %v, got %v", start, created)
+		}
+		assertHops(t, errx.GetHops(wrapped), start, errx.HopWrap, errx.HopWrap)
+
+		if hops := errx.GetHops(err); len(hops) != 0 {
+			t.Errorf("expected original error to be unchanged, got %v", hops)
+		}
+	})
+
+	t.Run("scoped creation and wraps", func(t *testing.T) {
+		errx.SetClock(tickingClock(start))
+		scope := errx.NewScope("billing")
+
+		err := scope.New("error")
+		if hops := errx.GetHops(err); len(hops) != 0 {
+			t.Errorf("expected no hops on creation, got %v", hops)
+		}
+
+		wrapped := scope.Wrap(err)
+		if created, _ := errx.GetCreated(wrapped); !created.Equal(start) {
+			t.Errorf("expected creation time %v, got %v", start, created)
+		}
+		assertHops(t, errx.GetHops(wrapped), start, errx.HopWrap)
+	})
+
+	t.Run("wrapping a standard error", func(t *testing.T) {
+		errx.SetClock(tickingClock(start))
+
+		err := errx.Wrap(fmt.Errorf("standard"))
+		if created, _ := errx.GetCreated(err); !created.Equal(start) {
+			t.Errorf("unexpected creation time: %v", created)
+		}
+		assertHops(t, errx.GetHops(err), start, errx.HopWrap)
+	})
+
+	t.Run("carried over gRPC", func(t *testing.T) {
+		errx.SetClock(tickingClock(start))
+
+		err := errx.Wrap(errx.New("error"))
+		_, received := errx.FromGRPCError(errx.ToGRPCError(err))
+
+		if created, _ := errx.GetCreated(received); !created.Equal(start) {
+			t.Errorf("unexpected creation time: %v", created)
+		}
+		assertHops(t, errx.GetHops(received), start, errx.HopWrap, errx.HopSend, errx.HopReceive)
+	})
+
+	t.Run("carried over JSON", func(t *testing.T) {
+		errx.SetClock(tickingClock(start))
+
+		data, merr := json.Marshal(errx.Wrap(errx.New("error")))
+		if merr != nil {
+			t.Fatal(merr)
+		}
+		_, received := errx.FromJSON(data)
+
+		if created, _ := errx.GetCreated(received); !created.Equal(start) {
+			t.Errorf("unexpected creation time: %v", created)
+		}
+		assertHops(t, errx.GetHops(received), start, errx.HopWrap, errx.HopReceive)
+	})
+
+	t.Run("unknown creation time", func(t *testing.T) {
+		_, received := errx.FromJSON([]byte(`{"code":"X","message":"old peer","type":"T_Internal"}`))
+		if _, ok := errx.GetCreated(received); ok {
+			t.Errorf("expected no creation time")
+		}
+		if _, ok := errx.GetCreated(fmt.Errorf("standard")); ok {
+			t.Errorf("expected no creation time")
+		}
+	})
+}
+
+func TestSetClock(t *testing.T) {
+	fixed := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	errx.SetClock(func() time.Time { return fixed })
+	if created, _ := errx.GetCreated(errx.New("error")); !created.Equal(fixed) {
+		t.Errorf("expected fixed time, got %v", created)
+	}
+
+	errx.SetClock(nil)
+	before := time.Now()
+	if created, _ := errx.GetCreated(errx.New("error")); created.Before(before) || created.After(time.Now()) {
+		t.Errorf("expected current time, got %v", created)
+	}
+}
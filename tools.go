@@ -1,41 +1,75 @@
 package errx
 
-import "slices"
+import (
+	"errors"
+	"slices"
+)
 
-// GetCode returns the error code if the error implements the ErrorX interface.
-// Otherwise, it returns the default code.
+// GetCode returns the code of the first ErrorX in the error's chain.
+// If there is none, it returns the default code.
+//
+// The chain is walked like errors.As does, so errors wrapped with fmt.Errorf("...: %w", err)
+// or joined with errors.Join are seen through.
 func GetCode(err error) string {
-	if e, ok := err.(ErrorX); ok {
+	if e, ok := Find[ErrorX](err); ok {
 		return e.Code()
 	}
 	return DefaultCode
 }
 
-// GetType returns the error type if the error implements the ErrorX interface.
-// Otherwise, it returns the default type.
+// GetType returns the type of the first ErrorX in the error's chain.
+// If there is none, it returns the default type.
 func GetType(err error) Type {
-	if e, ok := err.(ErrorX); ok {
+	if e, ok := Find[ErrorX](err); ok {
 		return e.Type()
 	}
 	return DefaultType
 }
 
-// IsCodeIn checks if the error's code is in the given list of codes.
+// IsCodeIn checks if the code of the first ErrorX in the error's chain is in the given list of codes.
+//
+// The list may contain wildcard patterns like "billing.*" (see MatchCode).
+// Aliases declared in the catalog are normalized to their canonical code on both sides.
+// To check every error of a tree, use HasCode.
 func IsCodeIn(err error, codes ...string) bool {
-	code := GetCode(err)
-	return slices.Contains(codes, code)
+	return codeIn(GetCode(err), codes)
+}
+
+// HasCode reports whether any ErrorX in the error's tree has one of the given codes.
+//
+// Unlike IsCodeIn, which only looks at the first ErrorX, it checks every ErrorX reachable
+// through Unwrap, including all branches of joined errors.
+// The codes may be wildcard patterns, and aliases are normalized like in IsCodeIn.
+func HasCode(err error, codes ...string) bool {
+	return walkErrors(err, func(err error) bool {
+		e, ok := err.(ErrorX)
+		return ok && codeIn(e.Code(), codes)
+	})
+}
+
+// Find returns the first error in the error's chain that has the type T.
+// T is usually an interface, like ErrorX, or a pointer to an error struct.
+//
+// The chain is walked like errors.As does. If no error matches, Find returns false.
+func Find[T error](err error) (T, bool) {
+	var target T
+	if err == nil {
+		return target, false
+	}
+	ok := errors.As(err, &target)
+	return target, ok
 }
 
-// AsErrorX returns the error as an ErrorX instance.
+// AsErrorX returns the first ErrorX in the error's chain.
 //
-// If the error does not implement the ErrorX interface,
-// it converts it to an ErrorX with default values.
+// If there is no ErrorX in the chain,
+// it converts the error to an ErrorX with default values.
 //
 // This function is useful when you want to work with ErrorX instances.
 //
 // ***NOTE***: Make sure that error is not nil before calling this function.
 func AsErrorX(err error) ErrorX {
-	if e, ok := err.(ErrorX); ok {
+	if e, ok := Find[ErrorX](err); ok {
 		return e
 	}
 
@@ -67,5 +101,38 @@ func WrapWithTypeOnCodes(err error, type_ Type, codes ...string) error {
 
 	e = e.clone()
 	e.addTrace(2)
+	e.addHop(HopWrap)
+	e.settleTrace()
 	return e
 }
+
+// codeIn reports whether the code matches any of the code patterns.
+func codeIn(code string, patterns []string) bool {
+	code = CanonicalCode(code)
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return MatchCode(code, CanonicalCode(pattern))
+	})
+}
+
+// walkErrors calls fn for every error in the tree of err, depth first,
+// until fn returns true. It reports whether fn returned true.
+func walkErrors(err error, fn func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+	if fn(err) {
+		return true
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrors(u.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if walkErrors(e, fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package errx_test
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"testing"
 
 	"github.com/code19m/errx"
@@ -116,3 +118,97 @@ func TestWrapWithTypeOnCodes(t *testing.T) {
 		}
 	})
 }
+
+func TestChainAwareAccessors(t *testing.T) {
+	inner := errx.New("user not found", errx.WithCode("USER_NOT_FOUND"), errx.WithType(errx.T_NotFound))
+	wrapped := fmt.Errorf("load profile: %w", inner)
+
+	t.Run("accessors see through fmt.Errorf", func(t *testing.T) {
+		if code := errx.GetCode(wrapped); code != "USER_NOT_FOUND" {
+			t.Errorf("expected code USER_NOT_FOUND, got %v", code)
+		}
+		if typ := errx.GetType(wrapped); typ != errx.T_NotFound {
+			t.Errorf("expected type T_NotFound, got %v", typ)
+		}
+		if !errx.IsCodeIn(wrapped, "USER_NOT_FOUND") {
+			t.Errorf("expected code to be in the list")
+		}
+		if errx.AsErrorX(wrapped).Code() != "USER_NOT_FOUND" {
+			t.Errorf("expected AsErrorX to return the wrapped ErrorX")
+		}
+	})
+
+	t.Run("accessors see through joined errors", func(t *testing.T) {
+		joined := errors.Join(errors.New("plain"), wrapped)
+		if code := errx.GetCode(joined); code != "USER_NOT_FOUND" {
+			t.Errorf("expected code USER_NOT_FOUND, got %v", code)
+		}
+	})
+
+	t.Run("Wrap inherits from a wrapped ErrorX", func(t *testing.T) {
+		err := errx.Wrap(wrapped)
+		if errx.GetCode(err) != "USER_NOT_FOUND" || errx.GetType(err) != errx.T_NotFound {
+			t.Errorf("expected code and type to be inherited, got %v %v", errx.GetCode(err), errx.GetType(err))
+		}
+		if err.Error() != "load profile: user not found" {
+			t.Errorf("unexpected message: %v", err.Error())
+		}
+		if !errors.Is(err, wrapped) {
+			t.Errorf("expected wrapped error to be in the chain")
+		}
+	})
+
+	t.Run("errors.Is sees errors wrapped by Wrap", func(t *testing.T) {
+		err := errx.Wrap(fmt.Errorf("open config: %w", fs.ErrNotExist))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected fs.ErrNotExist to be in the chain")
+		}
+	})
+}
+
+func TestFind(t *testing.T) {
+	t.Run("find ErrorX", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", errx.New("inner", errx.WithCode("INNER")))
+		e, ok := errx.Find[errx.ErrorX](err)
+		if !ok || e.Code() != "INNER" {
+			t.Errorf("expected to find ErrorX, got %v %v", e, ok)
+		}
+	})
+
+	t.Run("find concrete error type", func(t *testing.T) {
+		err := errx.Wrap(&fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: fs.ErrPermission})
+		pe, ok := errx.Find[*fs.PathError](err)
+		if !ok || pe.Path != "/etc/app.yaml" {
+			t.Errorf("expected to find *fs.PathError, got %v %v", pe, ok)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, ok := errx.Find[errx.ErrorX](errors.New("plain")); ok {
+			t.Errorf("expected no ErrorX")
+		}
+		if _, ok := errx.Find[errx.ErrorX](nil); ok {
+			t.Errorf("expected no ErrorX for nil")
+		}
+	})
+}
+
+func TestHasCode(t *testing.T) {
+	err := errors.Join(
+		errx.New("a", errx.WithCode("billing.CARD_DECLINED")),
+		fmt.Errorf("b: %w", errx.New("b", errx.WithCode("USER_NOT_FOUND"))),
+	)
+
+	if !errx.HasCode(err, "USER_NOT_FOUND") {
+		t.Errorf("expected code in the second branch to be found")
+	}
+	if !errx.HasCode(err, "billing.*") {
+		t.Errorf("expected pattern to match")
+	}
+	if errx.IsCodeIn(err, "USER_NOT_FOUND") {
+		t.Errorf("expected IsCodeIn to look at the first ErrorX only")
+	}
+	if errx.HasCode(err, "OTHER") || errx.HasCode(nil, "USER_NOT_FOUND") {
+		t.Errorf("expected no match")
+	}
+}
@@ -1,11 +1,130 @@
 package errx
 
 import (
-	"fmt"
 	"runtime"
 	"strings"
+	"sync"
 )
 
+// Frame is a single call site recorded in the error's trace.
+type Frame struct {
+	// File is the base name of the source file, e.g. "service.go".
+	File string `json:"file"`
+
+	// Path is the full path of the source file.
+	Path string `json:"path,omitempty"`
+
+	// Line is the line number in the source file.
+	Line int `json:"line"`
+
+	// Function is the package-qualified function name,
+	// e.g. "github.com/acme/app/users.(*Service).Get".
+	Function string `json:"function"`
+
+	// Package is the import path of the function's package, e.g. "github.com/acme/app/users".
+	Package string `json:"package,omitempty"`
+
+	// Service is the service the frame was recorded in, as set by WithTracePrefix.
+	// It is empty for frames of the current service.
+	Service string `json:"service,omitempty"`
+
+	// Repeat is the number of consecutive calls of the same call site the frame stands for,
+	// if they were collapsed into one (see TraceFilter.CollapseRepeats). It is zero otherwise.
+	Repeat int `json:"repeat,omitempty"`
+
+	// Omitted is set on a placeholder frame to the number of frames it replaces
+	// (see TraceFilter.MaxDepth). Placeholder frames have no call site.
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// ShortFunction returns the function name without the package path,
+// e.g. "users.(*Service).Get".
+func (f Frame) ShortFunction() string {
+	return f.Function[strings.LastIndex(f.Function, "/")+1:]
+}
+
+// String returns the frame in the default trace format, e.g. "[service.go:12] users.(*Service).Get".
+func (f Frame) String() string {
+	return TraceRenderer{}.RenderFrame(f)
+}
+
+// GetFrames returns the call sites recorded in the trace of the first ErrorX in the error's chain,
+// the most recent first.
+func GetFrames(err error) []Frame {
+	if e, ok := Find[*errorX](err); ok {
+		return e.Frames()
+	}
+	return nil
+}
+
+// caller is a call site recorded in the error's trace.
+//
+// Call sites recorded in this process hold only the program counter,
+// which is symbolized when the trace is read (see resolvePC).
+// Call sites received from peers hold the frame as received.
+// The repeat and omitted counts are set by the trace filter (see TraceFilter.capture).
+type caller struct {
+	pc      uintptr
+	frame   Frame
+	service string
+	repeat  int
+	omitted int
+}
+
+// resolve returns the frame of the call site.
+func (c caller) resolve() Frame {
+	if c.omitted > 0 {
+		return Frame{Omitted: c.omitted, Service: c.service}
+	}
+
+	f := c.frame
+	if c.pc != 0 {
+		f = resolvePC(c.pc)
+	}
+	f.Service = c.service
+	if c.repeat > 0 {
+		f.Repeat = c.repeat
+	}
+	return f
+}
+
+// frameCache caches the frames of symbolized program counters.
+// The number of call sites in a program is bounded, so the cache does not need eviction.
+var frameCache sync.Map // map[uintptr]Frame
+
+// resolvePC returns the frame of a program counter returned by runtime.Callers.
+func resolvePC(pc uintptr) Frame {
+	if f, ok := frameCache.Load(pc); ok {
+		return f.(Frame)
+	}
+
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	frame := unknownFrame
+	if f.Function != "" {
+		frame = newFrame(f.File, f.Line, f.Function)
+	}
+	frameCache.Store(pc, frame)
+	return frame
+}
+
+// callersOf converts received frames, the most recent first, to call sites, the oldest first.
+func callersOf(frames []Frame) []caller {
+	callers := make([]caller, len(frames))
+	for i, f := range frames {
+		callers[len(frames)-1-i] = caller{frame: f, service: f.Service}
+	}
+	return callers
+}
+
+// resolveFrames returns the frames of the call sites, the most recent first.
+func resolveFrames(callers []caller) []Frame {
+	frames := make([]Frame, len(callers))
+	for i, c := range callers {
+		frames[len(callers)-1-i] = c.resolve()
+	}
+	return frames
+}
+
 // addTrace captures and records the caller's context at a specific point in the error's propagation.
 // It collects detailed information about the function that triggered the error trace,
 // helping developers understand the exact path of error generation.
@@ -17,45 +136,65 @@ import (
 //   - 2: caller of the function that invoked addTrace
 //   - 3: caller of the function that invoked the function that invoked addTrace
 //
-// The method builds a compact, human-readable trace that includes:
-//   - Filename
-//   - Line number
-//   - Function name (without full package path)
+// Only the program counter of the caller is recorded, which is cheap.
+// If tracing is off for all types (see TraceConfig), nothing is recorded.
+// Otherwise the call site is kept or dropped by settleTrace, according to the mode of the error's final type.
+// It is symbolized into a Frame with the file name and full path, line number,
+// and function and package name only when the trace is read,
+// so errors that are handled and never logged don't pay for it.
 //
-// The trace is constructed in chronological order,
-// allowing developers to see the most recent call first.
+// Call sites are appended in chronological order,
+// and the trace is rendered with the most recent call first.
 func (e *errorX) addTrace(skipNumber int) {
-	// Retrieve caller information using runtime reflection
-	// Panics if unable to obtain caller details to prevent silent failures
-	pc, filepath, line, ok := runtime.Caller(skipNumber)
-	if !ok {
-		panic("could not get runtime.Caller")
+	if currentTraceConfig().tracingOff() {
+		return
 	}
 
-	// Retrieve function details for the program counter
-	// Ensures we have valid function metadata for tracing
-	fn := runtime.FuncForPC(pc)
-	if fn == nil {
-		panic("could not get runtime.FuncForPC")
+	// Retrieve the caller's program counter
+	// Records an unknown frame if unable to obtain caller details, rather than failing
+	var pcs [1]uintptr
+	if runtime.Callers(skipNumber+1, pcs[:]) == 0 {
+		e.callers = append(e.callers, caller{frame: unknownFrame})
+	} else {
+		e.callers = append(e.callers, caller{pc: pcs[0]})
 	}
+	e.tracePending = true
+}
 
-	// Extract filename without full path to keep trace compact
-	_, filename := pathSplit(filepath)
+// unknownFrame is recorded for call sites whose details cannot be obtained.
+var unknownFrame = Frame{File: "unknown", Function: "unknown"}
 
-	// Get function name, removing package prefix for readability
-	funcName := fn.Name()
-	shortFuncName := funcName[strings.LastIndex(funcName, "/")+1:]
+// newFrame creates a frame from a source location and a package-qualified function name.
+func newFrame(path string, line int, function string) Frame {
+	_, file := pathSplit(path)
+	return Frame{
+		File:     file,
+		Path:     path,
+		Line:     line,
+		Function: function,
+		Package:  funcPackage(function),
+	}
+}
 
-	// Format caller information with filename, line, and function name
-	callerInfo := fmt.Sprintf("[%s:%d] %s", filename, line, shortFuncName)
+// funcPackage returns the package import path of a package-qualified function name.
+func funcPackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return function[:slash+1+dot]
+}
 
-	// Prepend new trace information, creating a chain of function calls
-	// Uses right-pointing arrow (➡️) to visually represent call progression
-	if e.trace == "" {
-		e.trace = callerInfo
-	} else {
-		e.trace = fmt.Sprintf("%s ➡️ %s", callerInfo, e.trace)
+// rawTraceOf returns the part of a received trace string that is not covered by the received frames.
+// Peers send the trace both rendered and as frames, so the rendered frames are a prefix of the trace.
+// Peers that do not send frames send only the trace string, which is returned as a whole.
+func rawTraceOf(trace string, frames []Frame) string {
+	rest, ok := strings.CutPrefix(trace, TraceRenderer{}.RenderFrames(frames))
+	if !ok {
+		return ""
 	}
+	return strings.TrimPrefix(rest, DefaultTraceSeparator)
 }
 
 // pathSplit splits a path into the directory and the file name
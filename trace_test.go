@@ -1,6 +1,9 @@
 package errx_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -73,3 +76,146 @@ func innerErrorGenerator() error {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestFrames(t *testing.T) {
+	t.Run("frames are structured", func(t *testing.T) {
+		err := generateErrorThroughChain()
+		frames := errx.GetFrames(err)
+		if len(frames) != 3 {
+			t.Fatalf("expected 3 frames, got %d: %v", len(frames), frames)
+		}
+
+		f := frames[2]
+		if f.File != "trace_test.go" || !strings.HasSuffix(f.Path, "/trace_test.go") || f.Line == 0 {
+			t.Errorf("unexpected location: %+v", f)
+		}
+		if f.Function != "github.com/code19m/errx_test.innerErrorGenerator" || f.Package != "github.com/code19m/errx_test" {
+			t.Errorf("unexpected function: %+v", f)
+		}
+		if f.ShortFunction() != "errx_test.innerErrorGenerator" {
+			t.Errorf("unexpected short function: %v", f.ShortFunction())
+		}
+		if frames[0].ShortFunction() != "errx_test.generateErrorThroughChain" {
+			t.Errorf("expected most recent frame first, got: %v", frames[0])
+		}
+	})
+
+	t.Run("trace is rendered from frames", func(t *testing.T) {
+		err := generateErrorThroughChain()
+		e := err.(errx.ErrorX)
+
+		parts := make([]string, 0, 3)
+		for _, f := range errx.GetFrames(e) {
+			parts = append(parts, f.String())
+		}
+		if want := strings.Join(parts, " ➡️ "); e.Trace() != want {
+			t.Errorf("expected %q, got %q", want, e.Trace())
+		}
+	})
+
+	t.Run("service markers", func(t *testing.T) {
+		remote := errx.New("remote")
+		local := errx.Wrap(remote, errx.WithTracePrefix("users"))
+		local = errx.Wrap(local)
+
+		frames := errx.GetFrames(local)
+		if frames[0].Service != "" || frames[1].Service != "users" || frames[2].Service != "users" {
+			t.Errorf("unexpected services: %+v", frames)
+		}
+		if want := frames[0].String() + " ➡️ >>> users >>> " + frames[1].String() + " ➡️ " + frames[2].String(); local.(errx.ErrorX).Trace() != want {
+			t.Errorf("expected %q, got %q", want, local.(errx.ErrorX).Trace())
+		}
+	})
+
+	t.Run("frames are copied", func(t *testing.T) {
+		err := errx.New("error")
+		frames := errx.GetFrames(err)
+		frames[0].File = "changed.go"
+		if errx.GetFrames(err)[0].File != "trace_test.go" {
+			t.Errorf("expected frames of the error to be unchanged")
+		}
+	})
+}
+
+func TestFramesEncoding(t *testing.T) {
+	src := errx.Wrap(errx.New("remote"), errx.WithTracePrefix("users"))
+	want := errx.GetFrames(src)
+
+	t.Run("gRPC", func(t *testing.T) {
+		_, err := errx.FromGRPCError(errx.ToGRPCError(src))
+		frames := errx.GetFrames(err)
+		// ToGRPCError and FromGRPCError each record a frame.
+		if len(frames) != len(want)+2 || frames[2] != want[0] || frames[3] != want[1] {
+			t.Errorf("unexpected frames: %+v", frames)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, _ := json.Marshal(src)
+		_, err := errx.FromJSON(data)
+		frames := errx.GetFrames(err)
+		if len(frames) != len(want)+1 || frames[1] != want[0] || frames[2] != want[1] {
+			t.Errorf("unexpected frames: %+v", frames)
+		}
+		if !strings.HasSuffix(err.(errx.ErrorX).Trace(), src.(errx.ErrorX).Trace()) {
+			t.Errorf("unexpected trace: %v", err.(errx.ErrorX).Trace())
+		}
+	})
+
+	t.Run("trace string without frames", func(t *testing.T) {
+		_, err := errx.FromJSON([]byte(`{"code":"X","message":"m","type":"T_Internal","trace":">>> legacy >>> [a.go:1] a.f"}`))
+		e := err.(errx.ErrorX)
+		if !strings.HasSuffix(e.Trace(), " ➡️ >>> legacy >>> [a.go:1] a.f") {
+			t.Errorf("expected legacy trace to be kept, got: %v", e.Trace())
+		}
+		if frames := errx.GetFrames(e); len(frames) != 2 || frames[1] != (errx.Frame{File: "a.go", Line: 1, Function: "a.f", Package: "a", Service: "legacy"}) {
+			t.Errorf("expected legacy trace to be parsed, got: %+v", frames)
+		}
+	})
+
+	t.Run("unparsable trace string", func(t *testing.T) {
+		_, err := errx.FromJSON([]byte(`{"code":"X","message":"m","type":"T_Internal","trace":"something else"}`))
+		e := err.(errx.ErrorX)
+		if len(errx.GetFrames(e)) != 1 || !strings.HasSuffix(e.Trace(), " ➡️ something else") {
+			t.Errorf("expected raw trace to be kept, got: %v", e.Trace())
+		}
+	})
+}
+
+// BenchmarkTrace measures creating an error and wrapping it three times.
+// Errors that are never logged only record program counters, which is compared to
+// the eager symbolization of every call site done by earlier versions.
+func BenchmarkTrace(b *testing.B) {
+	b.Run("never logged", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = generateErrorThroughChain()
+		}
+	})
+
+	b.Run("logged", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = generateErrorThroughChain().(errx.ErrorX).Trace()
+		}
+	})
+
+	b.Run("eager baseline", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = eagerTrace(eagerTrace(eagerTrace(eagerTrace(""))))
+		}
+	})
+}
+
+// eagerTrace symbolizes the caller and prepends it to the trace,
+// like addTrace did before call sites were symbolized lazily.
+func eagerTrace(trace string) string {
+	pc, path, line, _ := runtime.Caller(1)
+	name := runtime.FuncForPC(pc).Name()
+	frame := fmt.Sprintf("[%s:%d] %s", path[strings.LastIndex(path, "/")+1:], line, name[strings.LastIndex(name, "/")+1:])
+	if trace == "" {
+		return frame
+	}
+	return fmt.Sprintf("%s ➡️ %s", frame, trace)
+}
@@ -0,0 +1,150 @@
+package errx
+
+import (
+	"maps"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// TraceMode controls what is recorded in the trace of an error.
+type TraceMode int
+
+const (
+	// TraceFrame records a single frame for each New, Wrap and conversion.
+	// This is the default mode.
+	TraceFrame TraceMode = iota
+
+	// TraceOff records nothing. Errors are cheapest to create, but have an empty trace.
+	TraceOff
+
+	// TraceStack records a frame for each New, Wrap and conversion, like TraceFrame,
+	// and captures the full call stack when the error is created (see Stack).
+	TraceStack
+)
+
+// TraceConfig is the tracing configuration, set with SetTraceConfig.
+// The zero value records a single frame per call for all types, which is the default.
+type TraceConfig struct {
+	// Mode is the trace mode for all types without an override.
+	Mode TraceMode
+
+	// Types overrides the mode for the given types, for example to turn tracing off
+	// for expected errors on hot paths, or to capture stacks for internal errors only:
+	//
+	//	errx.SetTraceConfig(errx.TraceConfig{
+	//	    Types: map[errx.Type]errx.TraceMode{
+	//	        errx.T_NotFound: errx.TraceOff,
+	//	        errx.T_Internal: errx.TraceStack,
+	//	    },
+	//	})
+	Types map[Type]TraceMode
+
+	// StackSampleRate is the fraction of errors in TraceStack mode whose stack is captured,
+	// between 0 and 1. The other errors are traced as in TraceFrame mode.
+	// Zero means that all stacks are captured.
+	StackSampleRate float64
+
+	// Filter holds the rules applied to call sites when they are recorded,
+	// and to traces rendered by ErrorX.Trace, for example to leave out middleware:
+	//
+	//	errx.SetTraceConfig(errx.TraceConfig{
+	//	    Filter: errx.TraceFilter{
+	//	        DropPackages:    []string{"github.com/acme/app/middleware/..."},
+	//	        CollapseRepeats: true,
+	//	        MaxDepth:        20,
+	//	    },
+	//	})
+	Filter TraceFilter
+}
+
+// traceConfig holds the tracing configuration set with SetTraceConfig.
+var traceConfig atomic.Pointer[TraceConfig]
+
+// SetTraceConfig sets the tracing configuration. It replaces the previous configuration,
+// including the one set with EnableStackCapture.
+func SetTraceConfig(c TraceConfig) {
+	c.Types = maps.Clone(c.Types)
+	c.Filter = c.Filter.clone()
+	traceConfig.Store(&c)
+}
+
+// GetTraceConfig returns the current tracing configuration.
+func GetTraceConfig() TraceConfig {
+	c := currentTraceConfig()
+	c.Types = maps.Clone(c.Types)
+	c.Filter = c.Filter.clone()
+	return c
+}
+
+// currentTraceConfig returns the tracing configuration without copying it.
+// The returned configuration must not be modified.
+func currentTraceConfig() TraceConfig {
+	if c := traceConfig.Load(); c != nil {
+		return *c
+	}
+	return TraceConfig{}
+}
+
+// updateTraceConfig atomically updates the tracing configuration.
+// The configuration passed to fn is a copy, which fn may modify.
+func updateTraceConfig(fn func(*TraceConfig)) {
+	for {
+		old := traceConfig.Load()
+		c := TraceConfig{}
+		if old != nil {
+			c = *old
+		}
+		c.Types = maps.Clone(c.Types)
+		c.Filter = c.Filter.clone()
+		fn(&c)
+		if traceConfig.CompareAndSwap(old, &c) {
+			return
+		}
+	}
+}
+
+// modeFor returns the trace mode for the type.
+func (c TraceConfig) modeFor(t Type) TraceMode {
+	if mode, ok := c.Types[t]; ok {
+		return mode
+	}
+	return c.Mode
+}
+
+// tracingOff reports whether tracing is off for all types,
+// in which case no call site needs to be recorded at all.
+func (c TraceConfig) tracingOff() bool {
+	if c.Mode != TraceOff {
+		return false
+	}
+	for _, mode := range c.Types {
+		if mode != TraceOff {
+			return false
+		}
+	}
+	return true
+}
+
+// sampleStack reports whether the stack of an error in TraceStack mode should be captured.
+func (c TraceConfig) sampleStack() bool {
+	return c.StackSampleRate <= 0 || c.StackSampleRate >= 1 || rand.Float64() < c.StackSampleRate
+}
+
+// settleTrace applies the trace mode of the error's final type to the call site
+// recorded by the last addTrace: it is dropped if tracing is off for the type,
+// and filtered by the rules of the tracing configuration otherwise.
+//
+// It is called once the options are applied, as they may change the type and the service.
+func (e *errorX) settleTrace() {
+	if !e.tracePending {
+		return
+	}
+	e.tracePending = false
+
+	c := currentTraceConfig()
+	if c.modeFor(e.type_) == TraceOff {
+		e.callers = e.callers[:len(e.callers)-1]
+		return
+	}
+	e.callers = c.Filter.capture(e.callers)
+}
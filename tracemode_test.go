@@ -0,0 +1,109 @@
+package errx_test
+
+import (
+	"testing"
+
+	"github.com/code19m/errx"
+)
+
+func TestTraceModes(t *testing.T) {
+	defer errx.SetTraceConfig(errx.TraceConfig{})
+
+	t.Run("default records a frame per call", func(t *testing.T) {
+		err := generateErrorThroughChain()
+		if n := len(errx.GetFrames(err)); n != 3 {
+			t.Errorf("expected 3 frames, got %d", n)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{Mode: errx.TraceOff})
+		err := generateErrorThroughChain()
+		if trace := err.(errx.ErrorX).Trace(); trace != "" {
+			t.Errorf("expected empty trace, got %v", trace)
+		}
+	})
+
+	t.Run("per type overrides", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{
+			Types: map[errx.Type]errx.TraceMode{
+				errx.T_NotFound: errx.TraceOff,
+				errx.T_Internal: errx.TraceStack,
+			},
+		})
+
+		notFound := errx.Wrap(errx.New("missing", errx.WithType(errx.T_NotFound)))
+		if len(errx.GetFrames(notFound)) != 0 || errx.GetStack(notFound).Len() != 0 {
+			t.Errorf("expected no trace for T_NotFound, got %v", notFound.(errx.ErrorX).Trace())
+		}
+
+		internal := errx.New("boom")
+		if len(errx.GetFrames(internal)) != 1 || errx.GetStack(internal).Len() == 0 {
+			t.Errorf("expected frame and stack for T_Internal")
+		}
+
+		conflict := errx.New("exists", errx.WithType(errx.T_Conflict))
+		if len(errx.GetFrames(conflict)) != 1 || errx.GetStack(conflict).Len() != 0 {
+			t.Errorf("expected a single frame for T_Conflict")
+		}
+
+		changed := errx.Wrap(internal, errx.WithType(errx.T_NotFound))
+		if len(errx.GetFrames(changed)) != 1 {
+			t.Errorf("expected earlier frames to be kept and the new one dropped, got %v", errx.GetFrames(changed))
+		}
+	})
+
+	t.Run("stack sampling", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{Mode: errx.TraceStack, StackSampleRate: 1e-12})
+		for i := 0; i < 100; i++ {
+			err := errx.New("boom")
+			if errx.GetStack(err).Len() != 0 {
+				t.Fatalf("expected stack not to be sampled")
+			}
+			if len(errx.GetFrames(err)) != 1 {
+				t.Fatalf("expected a frame for unsampled errors")
+			}
+		}
+
+		err := errx.New("boom", errx.WithStack())
+		if errx.GetStack(err).Len() == 0 {
+			t.Errorf("expected WithStack to bypass sampling")
+		}
+	})
+
+	t.Run("stack capture shorthand", func(t *testing.T) {
+		errx.SetTraceConfig(errx.TraceConfig{Types: map[errx.Type]errx.TraceMode{errx.T_NotFound: errx.TraceOff}})
+		errx.EnableStackCapture(errx.T_Internal)
+
+		c := errx.GetTraceConfig()
+		if c.Types[errx.T_Internal] != errx.TraceStack || c.Types[errx.T_NotFound] != errx.TraceOff {
+			t.Errorf("unexpected config: %+v", c)
+		}
+
+		errx.DisableStackCapture()
+		if c := errx.GetTraceConfig(); c.Types[errx.T_Internal] != errx.TraceFrame {
+			t.Errorf("unexpected config: %+v", c)
+		}
+	})
+}
+
+func BenchmarkTraceModes(b *testing.B) {
+	defer errx.SetTraceConfig(errx.TraceConfig{})
+
+	for _, mode := range []struct {
+		name string
+		mode errx.TraceMode
+	}{
+		{"off", errx.TraceOff},
+		{"frame", errx.TraceFrame},
+		{"stack", errx.TraceStack},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			errx.SetTraceConfig(errx.TraceConfig{Mode: mode.mode})
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = generateErrorThroughChain()
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package errx
 
-import "fmt"
+import (
+	"fmt"
+	"maps"
+	"sync/atomic"
+)
 
 const (
 	// Internal errors indicate unexpected issues within the application.
@@ -23,8 +27,29 @@ const (
 
 	// Throttling errors occur when a user has sent too many requests in a given time frame.
 	T_Throttling
+
+	// Unavailable errors occur when a dependency or the service itself is temporarily unavailable.
+	T_Unavailable
+
+	// Timeout errors occur when an operation did not complete before its deadline.
+	T_Timeout
+
+	// Canceled errors occur when an operation was canceled, typically by the caller.
+	T_Canceled
+
+	// PreconditionFailed errors occur when the system is not in the state required for an operation.
+	T_PreconditionFailed
+
+	// Unimplemented errors occur when an operation is not implemented or not supported.
+	T_Unimplemented
+
+	// TooLarge errors occur when a request or its payload exceeds the allowed size.
+	T_TooLarge
 )
 
+// lastType is the last built-in error type.
+const lastType = T_TooLarge
+
 const (
 	// DefaultCode is the default error code used when no code is provided.
 	DefaultCode = "UNSPECIFIED"
@@ -59,7 +84,66 @@ func (t Type) String() string {
 		return "T_Forbidden"
 	case T_Throttling:
 		return "T_Throttling"
+	case T_Unavailable:
+		return "T_Unavailable"
+	case T_Timeout:
+		return "T_Timeout"
+	case T_Canceled:
+		return "T_Canceled"
+	case T_PreconditionFailed:
+		return "T_PreconditionFailed"
+	case T_Unimplemented:
+		return "T_Unimplemented"
+	case T_TooLarge:
+		return "T_TooLarge"
 	default:
 		return fmt.Sprintf("Unknown Type (%d)", t)
 	}
 }
+
+// ParseType returns the Type whose String representation equals name.
+// The boolean result reports whether a matching type was found.
+func ParseType(name string) (Type, bool) {
+	for t := T_Internal; t <= lastType; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return DefaultType, false
+}
+
+// typeTable is a table keyed by error type that is read on every conversion.
+// It is replaced as a whole, so that it can be changed at runtime without data races.
+type typeTable[V any] struct {
+	current  atomic.Pointer[map[Type]V]
+	defaults map[Type]V
+}
+
+// get returns the current table. It must not be modified.
+func (t *typeTable[V]) get() map[Type]V {
+	if m := t.current.Load(); m != nil {
+		return *m
+	}
+	return t.defaults
+}
+
+// set replaces the table with a copy of m. A nil map restores the defaults.
+func (t *typeTable[V]) set(m map[Type]V) {
+	if m == nil {
+		t.current.Store(nil)
+		return
+	}
+	m = maps.Clone(m)
+	t.current.Store(&m)
+}
+
+// lookup returns the lowest type mapped to the value, as several types may share one.
+func lookup[V comparable](table map[Type]V, v V) (Type, bool) {
+	result, found := DefaultType, false
+	for t, tv := range table {
+		if tv == v && (!found || t < result) {
+			result, found = t, true
+		}
+	}
+	return result, found
+}
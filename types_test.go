@@ -18,6 +18,12 @@ func TestTypeString(t *testing.T) {
 		{errx.T_Authentication, "T_Authentication"},
 		{errx.T_Forbidden, "T_Forbidden"},
 		{errx.T_Throttling, "T_Throttling"},
+		{errx.T_Unavailable, "T_Unavailable"},
+		{errx.T_Timeout, "T_Timeout"},
+		{errx.T_Canceled, "T_Canceled"},
+		{errx.T_PreconditionFailed, "T_PreconditionFailed"},
+		{errx.T_Unimplemented, "T_Unimplemented"},
+		{errx.T_TooLarge, "T_TooLarge"},
 		{errx.Type(99), "Unknown Type (99)"},
 	}
 
@@ -29,3 +35,44 @@ func TestTypeString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseType(t *testing.T) {
+	for typ := errx.T_Internal; typ <= errx.T_TooLarge; typ++ {
+		got, ok := errx.ParseType(typ.String())
+		if !ok || got != typ {
+			t.Errorf("expected %v, got %v (ok=%v)", typ, got, ok)
+		}
+	}
+
+	if _, ok := errx.ParseType("T_Unknown"); ok {
+		t.Errorf("expected unknown type name to be rejected")
+	}
+}
+
+func TestTypeValues(t *testing.T) {
+	// Numeric values are part of the wire format and must never change.
+	tests := []struct {
+		typ      errx.Type
+		expected uint8
+	}{
+		{errx.T_Internal, 0},
+		{errx.T_Validation, 1},
+		{errx.T_NotFound, 2},
+		{errx.T_Conflict, 3},
+		{errx.T_Authentication, 4},
+		{errx.T_Forbidden, 5},
+		{errx.T_Throttling, 6},
+		{errx.T_Unavailable, 7},
+		{errx.T_Timeout, 8},
+		{errx.T_Canceled, 9},
+		{errx.T_PreconditionFailed, 10},
+		{errx.T_Unimplemented, 11},
+		{errx.T_TooLarge, 12},
+	}
+
+	for _, test := range tests {
+		if uint8(test.typ) != test.expected {
+			t.Errorf("expected %v to have value %d, got %d", test.typ, test.expected, uint8(test.typ))
+		}
+	}
+}
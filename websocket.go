@@ -0,0 +1,136 @@
+package errx
+
+import (
+	"maps"
+	"strings"
+	"unicode/utf8"
+)
+
+// WebSocket close codes used by the default mapping, as defined by RFC 6455.
+const (
+	WSCloseNormalClosure   = 1000
+	WSCloseGoingAway       = 1001
+	WSClosePolicyViolation = 1008
+	WSCloseInternalError   = 1011
+
+	// WSCloseReasonMaxLen is the maximum length in bytes of a close reason,
+	// as the close frame payload is limited to 125 bytes including the 2-byte code.
+	WSCloseReasonMaxLen = 123
+)
+
+// webSocketCloseCodes maps error types to WebSocket close codes (see WebSocketCloseCodes).
+var webSocketCloseCodes = typeTable[int]{defaults: map[Type]int{
+	T_Internal:           WSCloseInternalError,
+	T_Validation:         4400,
+	T_NotFound:           4404,
+	T_Conflict:           4409,
+	T_Authentication:     WSClosePolicyViolation,
+	T_Forbidden:          WSClosePolicyViolation,
+	T_Throttling:         4429,
+	T_Unavailable:        4503,
+	T_Timeout:            4504,
+	T_Canceled:           4499,
+	T_PreconditionFailed: 4412,
+	T_Unimplemented:      4501,
+	T_TooLarge:           4413,
+}}
+
+// WebSocketCloseCodes returns a copy of the table mapping error types to WebSocket close codes.
+//
+// By default, authentication and forbidden errors use the policy violation code (1008),
+// internal errors use the internal error code (1011), and the other types use
+// application codes from the 4000-4999 range.
+// Types missing from the table are mapped to WSCloseInternalError.
+func WebSocketCloseCodes() map[Type]int {
+	return maps.Clone(webSocketCloseCodes.get())
+}
+
+// SetWebSocketCloseCodes replaces the table mapping error types to WebSocket close codes,
+// for example to match the conventions of an existing client.
+// The map is copied. A nil map restores the default table.
+func SetWebSocketCloseCodes(codes map[Type]int) {
+	webSocketCloseCodes.set(codes)
+}
+
+// ToWebSocketClose converts an error into a WebSocket close code and reason.
+//
+// The close code is taken from the WebSocketCloseCodes table based on the error type.
+// The reason has the format "CODE: message" and is trimmed to WSCloseReasonMaxLen bytes
+// without splitting UTF-8 characters.
+// If the provided error does not implement the ErrorX interface, it is wrapped
+// into a default ErrorX instance.
+//
+// If the error is nil, the normal closure code (1000) and an empty reason are returned.
+func ToWebSocketClose(err error) (int, string) {
+	if err == nil {
+		return WSCloseNormalClosure, ""
+	}
+
+	e := errorXOf(err)
+
+	code, ok := webSocketCloseCodes.get()[e.Type()]
+	if !ok {
+		code = WSCloseInternalError
+	}
+
+	return code, trimReason(e.Code() + ": " + e.PublicMessage())
+}
+
+// FromWebSocketClose converts a WebSocket close code and reason into a custom error (ErrorX).
+//
+// It is intended for use on the client side when a connection is closed by the server.
+// The function returns a boolean indicating whether the reason carried an error code (`true`) or not (`false`).
+// The type is derived from the close code using the WebSocketCloseCodes table.
+// When several types share a close code, the one with the lowest value is used.
+//
+// The normal closure (1000) and going away (1001) codes are not errors: `false` and a nil error are returned,
+// so that the result of ToWebSocketClose(nil) round-trips.
+//
+// Optional modifications can be applied via OptionFunc.
+func FromWebSocketClose(closeCode int, reason string, opts ...OptionFunc) (bool, error) {
+	if closeCode == WSCloseNormalClosure || closeCode == WSCloseGoingAway {
+		return false, nil
+	}
+
+	msg := reason
+	code, rest, found := strings.Cut(reason, ": ")
+	found = found && code != "" && !strings.ContainsAny(code, " \t")
+	if found {
+		msg = rest
+	}
+
+	e := newDefault(msg)
+	e.type_ = webSocketCloseType(closeCode)
+	if found {
+		e.code = CanonicalCode(code)
+	}
+
+	e.addTrace(2)
+	applyOpts(e, opts)
+	return found, e
+}
+
+// webSocketCloseType returns the lowest type mapped to the given close code.
+func webSocketCloseType(closeCode int) Type {
+	t, _ := lookup(webSocketCloseCodes.get(), closeCode)
+	return t
+}
+
+// trimReason trims the reason to WSCloseReasonMaxLen bytes
+// without splitting a multi-byte UTF-8 character.
+func trimReason(reason string) string {
+	if len(reason) <= WSCloseReasonMaxLen {
+		return reason
+	}
+
+	reason = reason[:WSCloseReasonMaxLen]
+	for i := len(reason) - 1; i >= 0 && i >= len(reason)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(reason[i]) {
+			if !utf8.FullRuneInString(reason[i:]) {
+				reason = reason[:i]
+			}
+			break
+		}
+	}
+	return reason
+}
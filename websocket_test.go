@@ -0,0 +1,118 @@
+package errx_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/code19m/errx"
+)
+
+func TestToWebSocketClose(t *testing.T) {
+	t.Run("convert types to close codes", func(t *testing.T) {
+		testCases := []struct {
+			errType  errx.Type
+			expected int
+		}{
+			{errx.T_Internal, errx.WSCloseInternalError},
+			{errx.T_Authentication, errx.WSClosePolicyViolation},
+			{errx.T_Forbidden, errx.WSClosePolicyViolation},
+			{errx.T_Validation, 4400},
+			{errx.T_NotFound, 4404},
+			{errx.Type(99), errx.WSCloseInternalError},
+		}
+
+		for _, tc := range testCases {
+			code, _ := errx.ToWebSocketClose(errx.New("test error", errx.WithType(tc.errType)))
+			if code != tc.expected {
+				t.Errorf("for error type %v, expected close code %d, got %d", tc.errType, tc.expected, code)
+			}
+		}
+	})
+
+	t.Run("reason holds code and message", func(t *testing.T) {
+		_, reason := errx.ToWebSocketClose(errx.New("session expired", errx.WithCode("SESSION_EXPIRED")))
+		if reason != "SESSION_EXPIRED: Internal server error" {
+			t.Errorf("unexpected reason: %v", reason)
+		}
+	})
+
+	t.Run("nil error closes normally", func(t *testing.T) {
+		code, reason := errx.ToWebSocketClose(nil)
+		if code != errx.WSCloseNormalClosure || reason != "" {
+			t.Errorf("unexpected close: %d, %q", code, reason)
+		}
+	})
+
+	t.Run("custom code table", func(t *testing.T) {
+		codes := errx.WebSocketCloseCodes()
+		codes[errx.T_NotFound] = 4004
+		errx.SetWebSocketCloseCodes(codes)
+		defer errx.SetWebSocketCloseCodes(nil)
+
+		code, reason := errx.ToWebSocketClose(errx.New("missing", errx.WithType(errx.T_NotFound)))
+		if code != 4004 {
+			t.Errorf("expected custom code, got %d", code)
+		}
+		if _, err := errx.FromWebSocketClose(code, reason); errx.GetType(err) != errx.T_NotFound {
+			t.Errorf("expected type T_NotFound, got %v", errx.GetType(err))
+		}
+	})
+
+	t.Run("reason is trimmed to protocol limit", func(t *testing.T) {
+		_, reason := errx.ToWebSocketClose(errx.New(strings.Repeat("ё", 100)))
+		if len(reason) > errx.WSCloseReasonMaxLen {
+			t.Errorf("expected reason of at most %d bytes, got %d", errx.WSCloseReasonMaxLen, len(reason))
+		}
+		if !utf8.ValidString(reason) {
+			t.Errorf("expected valid UTF-8 reason, got %q", reason)
+		}
+
+		_, reason = errx.ToWebSocketClose(errx.New("", errx.WithPublicMessage("bad \xff byte "+strings.Repeat("a", 200))))
+		if len(reason) != errx.WSCloseReasonMaxLen {
+			t.Errorf("expected only a split character at the end to be dropped, got %d bytes", len(reason))
+		}
+	})
+}
+
+func TestFromWebSocketClose(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		code, reason := errx.ToWebSocketClose(errx.New("room not found", errx.WithCode("ROOM_NOT_FOUND"), errx.WithType(errx.T_NotFound)))
+
+		ok, err := errx.FromWebSocketClose(code, reason)
+		if !ok {
+			t.Fatalf("expected successful conversion")
+		}
+		e := err.(errx.ErrorX)
+		if e.Code() != "ROOM_NOT_FOUND" || e.Type() != errx.T_NotFound || e.Error() != "Resource not found" {
+			t.Errorf("unexpected error: %v, %v, %v", e.Code(), e.Type(), e.Error())
+		}
+	})
+
+	t.Run("normal closures are not errors", func(t *testing.T) {
+		code, reason := errx.ToWebSocketClose(nil)
+		if ok, err := errx.FromWebSocketClose(code, reason); ok || err != nil {
+			t.Errorf("expected nil error, got %v, %v", ok, err)
+		}
+		if ok, err := errx.FromWebSocketClose(errx.WSCloseGoingAway, "server restart"); ok || err != nil {
+			t.Errorf("expected nil error, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("policy violation maps to the lowest type", func(t *testing.T) {
+		_, err := errx.FromWebSocketClose(errx.WSClosePolicyViolation, "NO_ACCESS: no access")
+		if errx.GetType(err) != errx.T_Authentication {
+			t.Errorf("expected type T_Authentication, got %v", errx.GetType(err))
+		}
+	})
+
+	t.Run("plain reason", func(t *testing.T) {
+		ok, err := errx.FromWebSocketClose(4000, "session replaced")
+		if ok {
+			t.Errorf("expected unsuccessful conversion")
+		}
+		if errx.GetCode(err) != errx.DefaultCode || err.Error() != "session replaced" {
+			t.Errorf("unexpected error: %v, %v", errx.GetCode(err), err)
+		}
+	})
+}